@@ -1,5 +1,85 @@
+// Package kms 定义密钥管理接口，密钥本身保存在 KMS 中不离开服务端，
+// 目前的实现为 Vault Transit 引擎
 package kms
 
-func InitKMS() {
+import (
+	"context"
+	"errors"
+	"time"
+)
 
+var (
+	ErrNotInitialized   = errors.New("kms not initialized")
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrInvalidKeyName   = errors.New("invalid key name")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// KeyType 为密钥类型，取值与 Vault Transit 的 type 参数一致
+type KeyType string
+
+const (
+	// 对称密钥，用于 Encrypt / Decrypt
+	KeyAES256GCM        KeyType = "aes256-gcm96"
+	KeyChaCha20Poly1305 KeyType = "chacha20-poly1305"
+
+	// 非对称密钥，用于 Sign / Verify
+	KeyEd25519   KeyType = "ed25519"
+	KeyECDSAP256 KeyType = "ecdsa-p256"
+	KeyRSA4096   KeyType = "rsa-4096"
+)
+
+// KeyVersion 为密钥的一个版本，PublicKey 仅非对称密钥有值
+type KeyVersion struct {
+	Version   int
+	CreatedAt time.Time
+	PublicKey string
+}
+
+// KeyManager 是密钥管理接口。密文和签名为 KMS 自描述的字符串
+// （Vault 中形如 vault:v1:...），其中带有密钥版本，轮换后旧数据仍可解密和验证
+type KeyManager interface {
+	// CreateKey 创建密钥，同名密钥已存在时不做任何修改
+	CreateKey(ctx context.Context, name string, keyType KeyType) error
+
+	// Encrypt 用最新版本的密钥加密，aad 为附加认证数据，可为空
+	Encrypt(ctx context.Context, name string, plaintext, aad []byte) (string, error)
+	Decrypt(ctx context.Context, name, ciphertext string, aad []byte) ([]byte, error)
+
+	// Sign 用最新版本的密钥签名，签名不匹配时 Verify 返回 ErrInvalidSignature
+	Sign(ctx context.Context, name string, data []byte) (string, error)
+	Verify(ctx context.Context, name string, data []byte, signature string) error
+
+	// Rotate 生成新版本的密钥，之后的加密和签名都使用新版本
+	Rotate(ctx context.Context, name string) error
+
+	// Versions 按版本号升序返回密钥的所有版本
+	Versions(ctx context.Context, name string) ([]KeyVersion, error)
+}
+
+// Default 为 InitKMS 创建的全局 KeyManager
+var Default KeyManager
+
+// InitKMS 用环境变量（见 VaultConfigFromEnv）连接 Vault 并设置 Default。
+// 地址错误或 token 无效时返回错误，Default 保持不变
+func InitKMS() error {
+	km, err := NewVaultTransit(VaultConfigFromEnv())
+	if err != nil {
+		return err
+	}
+
+	if err := km.Ping(context.Background()); err != nil {
+		return err
+	}
+
+	Default = km
+	return nil
+}
+
+// Manager 返回 Default，未调用 InitKMS 时返回 ErrNotInitialized
+func Manager() (KeyManager, error) {
+	if Default == nil {
+		return nil, ErrNotInitialized
+	}
+	return Default, nil
 }
@@ -0,0 +1,60 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInitKMS(t *testing.T) {
+	t.Cleanup(func() { Default = nil })
+
+	Default = nil
+	if _, err := Manager(); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("expect ErrNotInitialized, got %v", err)
+	}
+
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+	if err := InitKMS(); !errors.Is(err, ErrInvalidVaultConfig) {
+		t.Fatalf("expect ErrInvalidVaultConfig, got %v", err)
+	}
+
+	srv := newFakeVault(t)
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "expired-token")
+	t.Setenv("VAULT_NAMESPACE", "")
+	t.Setenv("KMS_TRANSIT_MOUNT", "")
+
+	// a bad token or an unreachable server fails at init, not on first use
+	var vaultErr *VaultError
+	if err := InitKMS(); !errors.As(err, &vaultErr) || vaultErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expect permission denied, got %v", err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	t.Setenv("VAULT_ADDR", closed.URL)
+	t.Setenv("VAULT_TOKEN", testToken)
+	if err := InitKMS(); err == nil {
+		t.Fatalf("expect error")
+	}
+	if Default != nil {
+		t.Fatalf("expect Default unset after failed init")
+	}
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	if err := InitKMS(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	km, err := Manager()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := km.CreateKey(context.Background(), "key", KeyAES256GCM); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTransitMount = "transit"
+	defaultVaultTimeout = 30 * time.Second
+
+	// maxVaultResponse 限制读取的响应大小，防止异常的服务端耗尽内存
+	maxVaultResponse = 64 << 20
+
+	// maxVaultErrorText 为非 JSON 错误响应保留到 VaultError 中的最大长度
+	maxVaultErrorText = 512
+)
+
+var ErrInvalidVaultConfig = errors.New("invalid vault config")
+
+// VaultError 为 Vault 返回的错误响应
+type VaultError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *VaultError) Error() string {
+	return fmt.Sprintf("vault: status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+type VaultConfig struct {
+	// Address 为 Vault 地址，如 http://127.0.0.1:8200
+	Address string
+	Token   string
+
+	// Namespace 为企业版的命名空间，可为空
+	Namespace string
+
+	// Mount 为 Transit 引擎的挂载路径，默认 transit
+	Mount string
+
+	// HTTPClient 为空时使用 30 秒超时的默认客户端，
+	// 需要 TLS 客户端证书时可传入 pki.ClientTLSConfig 配置的客户端
+	HTTPClient *http.Client
+}
+
+// VaultConfigFromEnv 从 VAULT_ADDR、VAULT_TOKEN、VAULT_NAMESPACE
+// 和 KMS_TRANSIT_MOUNT 读取配置
+func VaultConfigFromEnv() *VaultConfig {
+	return &VaultConfig{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Mount:     os.Getenv("KMS_TRANSIT_MOUNT"),
+	}
+}
+
+// VaultTransit 通过 HTTP API 使用 Vault Transit 引擎实现 KeyManager
+type VaultTransit struct {
+	// base 为 /v1，mount 为 Transit 引擎的挂载路径
+	base      *url.URL
+	mount     string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+var _ KeyManager = (*VaultTransit)(nil)
+
+func NewVaultTransit(cfg *VaultConfig) (*VaultTransit, error) {
+	if cfg == nil || cfg.Address == "" || cfg.Token == "" {
+		return nil, ErrInvalidVaultConfig
+	}
+
+	addr, err := url.Parse(cfg.Address)
+	if err != nil || (addr.Scheme != "http" && addr.Scheme != "https") || addr.Host == "" {
+		return nil, fmt.Errorf("%w: address %q", ErrInvalidVaultConfig, cfg.Address)
+	}
+
+	mount := strings.Trim(cfg.Mount, "/")
+	if mount == "" {
+		mount = defaultTransitMount
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultVaultTimeout}
+	}
+
+	return &VaultTransit{
+		base:      addr.JoinPath("v1"),
+		mount:     mount,
+		token:     cfg.Token,
+		namespace: cfg.Namespace,
+		client:    client,
+	}, nil
+}
+
+// CreateKey 对应 POST /transit/keys/:name
+func (v *VaultTransit) CreateKey(ctx context.Context, name string, keyType KeyType) error {
+	body := map[string]any{"type": string(keyType)}
+	return v.transit(ctx, http.MethodPost, []string{"keys", name}, body, nil)
+}
+
+// Encrypt 对应 POST /transit/encrypt/:name。与 Vault 一致，
+// 密钥不存在时会自动创建 aes256-gcm96 类型的密钥
+func (v *VaultTransit) Encrypt(ctx context.Context, name string, plaintext, aad []byte) (string, error) {
+	body := map[string]any{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	if len(aad) > 0 {
+		body["associated_data"] = base64.StdEncoding.EncodeToString(aad)
+	}
+
+	var out struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := v.transit(ctx, http.MethodPost, []string{"encrypt", name}, body, &out); err != nil {
+		return "", err
+	}
+
+	return out.Ciphertext, nil
+}
+
+// Decrypt 对应 POST /transit/decrypt/:name
+func (v *VaultTransit) Decrypt(ctx context.Context, name, ciphertext string, aad []byte) ([]byte, error) {
+	body := map[string]any{"ciphertext": ciphertext}
+	if len(aad) > 0 {
+		body["associated_data"] = base64.StdEncoding.EncodeToString(aad)
+	}
+
+	var out struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := v.transit(ctx, http.MethodPost, []string{"decrypt", name}, body, &out); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(out.Plaintext)
+}
+
+// Sign 对应 POST /transit/sign/:name，使用 Vault 默认的哈希和签名算法
+func (v *VaultTransit) Sign(ctx context.Context, name string, data []byte) (string, error) {
+	body := map[string]any{"input": base64.StdEncoding.EncodeToString(data)}
+
+	var out struct {
+		Signature string `json:"signature"`
+	}
+	if err := v.transit(ctx, http.MethodPost, []string{"sign", name}, body, &out); err != nil {
+		return "", err
+	}
+
+	return out.Signature, nil
+}
+
+// Verify 对应 POST /transit/verify/:name
+func (v *VaultTransit) Verify(ctx context.Context, name string, data []byte, signature string) error {
+	body := map[string]any{
+		"input":     base64.StdEncoding.EncodeToString(data),
+		"signature": signature,
+	}
+
+	var out struct {
+		Valid bool `json:"valid"`
+	}
+	if err := v.transit(ctx, http.MethodPost, []string{"verify", name}, body, &out); err != nil {
+		return err
+	}
+
+	if !out.Valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Ping 对应 GET /auth/token/lookup-self，检查 Vault 是否可达以及 token 是否有效
+func (v *VaultTransit) Ping(ctx context.Context) error {
+	if err := v.do(ctx, http.MethodGet, []string{"auth", "token", "lookup-self"}, nil, nil); err != nil {
+		return fmt.Errorf("vault: token lookup: %w", err)
+	}
+	return nil
+}
+
+// Rotate 对应 POST /transit/keys/:name/rotate
+func (v *VaultTransit) Rotate(ctx context.Context, name string) error {
+	return v.transit(ctx, http.MethodPost, []string{"keys", name, "rotate"}, nil, nil)
+}
+
+// Versions 对应 GET /transit/keys/:name
+func (v *VaultTransit) Versions(ctx context.Context, name string) ([]KeyVersion, error) {
+	var out struct {
+		Keys map[string]json.RawMessage `json:"keys"`
+	}
+	if err := v.transit(ctx, http.MethodGet, []string{"keys", name}, nil, &out); err != nil {
+		return nil, err
+	}
+
+	versions := make([]KeyVersion, 0, len(out.Keys))
+	for k, raw := range out.Keys {
+		version, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("vault: invalid key version %q", k)
+		}
+
+		kv, err := parseKeyVersion(raw)
+		if err != nil {
+			return nil, fmt.Errorf("vault: key version %d: %w", version, err)
+		}
+		kv.Version = version
+		versions = append(versions, kv)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// parseKeyVersion 解析 keys 中的一项：对称密钥为创建时间的 Unix 时间戳，
+// 非对称密钥为包含创建时间和公钥的对象
+func parseKeyVersion(raw json.RawMessage) (KeyVersion, error) {
+	var unix int64
+	if err := json.Unmarshal(raw, &unix); err == nil {
+		return KeyVersion{CreatedAt: time.Unix(unix, 0).UTC()}, nil
+	}
+
+	var entry struct {
+		CreationTime time.Time `json:"creation_time"`
+		PublicKey    string    `json:"public_key"`
+	}
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return KeyVersion{}, err
+	}
+
+	return KeyVersion{CreatedAt: entry.CreationTime, PublicKey: entry.PublicKey}, nil
+}
+
+// transit 调用 Transit 引擎的接口，path 为挂载路径之后的部分，path[1] 为密钥名
+func (v *VaultTransit) transit(ctx context.Context, method string, path []string, body, out any) error {
+	if name := path[1]; name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/?#") {
+		return fmt.Errorf("%w: %q", ErrInvalidKeyName, name)
+	}
+
+	err := v.do(ctx, method, append([]string{v.mount}, path...), body, out)
+
+	var vaultErr *VaultError
+	if !errors.As(err, &vaultErr) {
+		return err
+	}
+	if vaultErr.StatusCode == http.StatusNotFound {
+		return ErrKeyNotFound
+	}
+	for _, msg := range vaultErr.Errors {
+		// 对不存在的密钥加解密时 Vault 返回 400
+		if strings.Contains(msg, "key not found") {
+			return fmt.Errorf("%w: %s", ErrKeyNotFound, msg)
+		}
+	}
+	return err
+}
+
+// do 发送请求并把响应的 data 字段解析到 out，out 为 nil 时忽略响应内容
+func (v *VaultTransit) do(ctx context.Context, method string, path []string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.base.JoinPath(path...).String(), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("X-Vault-Request", "true")
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxVaultResponse))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Errors []string `json:"errors"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			// 代理等返回的非 JSON 错误页，保留原文的开头
+			raw := strings.TrimSpace(string(data[:min(len(data), maxVaultErrorText)]))
+			if raw != "" {
+				body.Errors = []string{raw}
+			}
+		}
+		return &VaultError{StatusCode: resp.StatusCode, Errors: body.Errors}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("vault: invalid response: %w", err)
+	}
+	if len(envelope.Data) == 0 {
+		return fmt.Errorf("vault: response without data")
+	}
+
+	return json.Unmarshal(envelope.Data, out)
+}
@@ -0,0 +1,424 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testToken = "test-token"
+
+// fakeTransit 是 Vault Transit HTTP API 的最小替身，
+// 只支持 aes256-gcm96 和 ed25519 密钥
+type fakeTransit struct {
+	mu   sync.Mutex
+	keys map[string]*fakeKey
+}
+
+type fakeKey struct {
+	keyType  KeyType
+	versions []fakeVersion
+}
+
+type fakeVersion struct {
+	secret  []byte
+	signer  ed25519.PrivateKey
+	created time.Time
+}
+
+func newFakeVault(t *testing.T) *httptest.Server {
+	f := &fakeTransit{keys: map[string]*fakeKey{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (f *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != testToken {
+		writeVault(w, http.StatusForbidden, nil, "permission denied")
+		return
+	}
+
+	if r.URL.Path == "/v1/auth/token/lookup-self" && r.Method == http.MethodGet {
+		writeVault(w, http.StatusOK, map[string]any{"id": testToken, "policies": []string{"root"}})
+		return
+	}
+
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/transit/"), "/")
+	if len(path) < 2 {
+		writeVault(w, http.StatusNotFound, nil)
+		return
+	}
+
+	var body map[string]string
+	if r.Method == http.MethodPost && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeVault(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	op, name := path[0], path[1]
+	if op == "keys" && r.Method == http.MethodPost && len(path) == 2 {
+		if _, ok := f.keys[name]; !ok {
+			key := &fakeKey{keyType: KeyType(body["type"])}
+			if key.keyType != KeyAES256GCM && key.keyType != KeyEd25519 {
+				writeVault(w, http.StatusBadRequest, nil, "unknown key type")
+				return
+			}
+			key.rotate()
+			f.keys[name] = key
+		}
+		writeVault(w, http.StatusNoContent, nil)
+		return
+	}
+
+	key, ok := f.keys[name]
+	switch {
+	case !ok && op == "encrypt":
+		// 与 Vault 一致，加密时自动创建不存在的密钥（upsert）
+		key = &fakeKey{keyType: KeyAES256GCM}
+		key.rotate()
+		f.keys[name] = key
+	case !ok && op == "keys":
+		writeVault(w, http.StatusNotFound, nil)
+		return
+	case !ok:
+		writeVault(w, http.StatusBadRequest, nil, "encryption key not found")
+		return
+	}
+
+	switch {
+	case op == "keys" && r.Method == http.MethodGet:
+		keys := map[string]any{}
+		for i, v := range key.versions {
+			if key.keyType == KeyEd25519 {
+				keys[strconv.Itoa(i+1)] = map[string]any{
+					"creation_time": v.created,
+					"public_key":    base64.StdEncoding.EncodeToString(v.signer.Public().(ed25519.PublicKey)),
+				}
+			} else {
+				keys[strconv.Itoa(i+1)] = v.created.Unix()
+			}
+		}
+		writeVault(w, http.StatusOK, map[string]any{"keys": keys, "latest_version": len(key.versions)})
+
+	case op == "keys" && len(path) == 3 && path[2] == "rotate":
+		key.rotate()
+		writeVault(w, http.StatusOK, map[string]any{"latest_version": len(key.versions)})
+
+	case op == "encrypt":
+		plaintext, _ := base64.StdEncoding.DecodeString(body["plaintext"])
+		aad, _ := base64.StdEncoding.DecodeString(body["associated_data"])
+		version := len(key.versions)
+		aead := key.versions[version-1].aead()
+
+		nonce := make([]byte, aead.NonceSize())
+		rand.Read(nonce)
+		sealed := aead.Seal(nonce, nonce, plaintext, aad)
+		writeVault(w, http.StatusOK, map[string]any{"ciphertext": fmt.Sprintf("vault:v%d:%s", version, base64.StdEncoding.EncodeToString(sealed))})
+
+	case op == "decrypt":
+		version, sealed, ok := key.parse(body["ciphertext"])
+		aad, _ := base64.StdEncoding.DecodeString(body["associated_data"])
+		if !ok || len(sealed) < 12 {
+			writeVault(w, http.StatusBadRequest, nil, "invalid ciphertext")
+			return
+		}
+		plaintext, err := key.versions[version-1].aead().Open(nil, sealed[:12], sealed[12:], aad)
+		if err != nil {
+			writeVault(w, http.StatusBadRequest, nil, "cipher: message authentication failed")
+			return
+		}
+		writeVault(w, http.StatusOK, map[string]any{"plaintext": base64.StdEncoding.EncodeToString(plaintext)})
+
+	case op == "sign":
+		input, _ := base64.StdEncoding.DecodeString(body["input"])
+		version := len(key.versions)
+		sig := ed25519.Sign(key.versions[version-1].signer, input)
+		writeVault(w, http.StatusOK, map[string]any{"signature": fmt.Sprintf("vault:v%d:%s", version, base64.StdEncoding.EncodeToString(sig))})
+
+	case op == "verify":
+		input, _ := base64.StdEncoding.DecodeString(body["input"])
+		version, sig, ok := key.parse(body["signature"])
+		if !ok {
+			writeVault(w, http.StatusBadRequest, nil, "invalid signature")
+			return
+		}
+		valid := ed25519.Verify(key.versions[version-1].signer.Public().(ed25519.PublicKey), input, sig)
+		writeVault(w, http.StatusOK, map[string]any{"valid": valid})
+
+	default:
+		writeVault(w, http.StatusNotFound, nil)
+	}
+}
+
+func (k *fakeKey) rotate() {
+	v := fakeVersion{created: time.Now().UTC().Truncate(time.Second)}
+	if k.keyType == KeyEd25519 {
+		_, v.signer, _ = ed25519.GenerateKey(rand.Reader)
+	} else {
+		v.secret = make([]byte, 32)
+		rand.Read(v.secret)
+	}
+	k.versions = append(k.versions, v)
+}
+
+// parse 拆分 vault:v<version>:<base64> 格式的密文或签名
+func (k *fakeKey) parse(s string) (int, []byte, bool) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+		return 0, nil, false
+	}
+	version, err := strconv.Atoi(parts[1][1:])
+	if err != nil || version < 1 || version > len(k.versions) {
+		return 0, nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	return version, data, err == nil
+}
+
+func (v fakeVersion) aead() cipher.AEAD {
+	block, _ := aes.NewCipher(v.secret)
+	aead, _ := cipher.NewGCM(block)
+	return aead
+}
+
+func writeVault(w http.ResponseWriter, status int, data any, errs ...string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if status == http.StatusNoContent {
+		return
+	}
+
+	resp := map[string]any{"errors": errs}
+	if data != nil {
+		resp = map[string]any{"data": data}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newTestTransit(t *testing.T) *VaultTransit {
+	srv := newFakeVault(t)
+	km, err := NewVaultTransit(&VaultConfig{Address: srv.URL, Token: testToken})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return km
+}
+
+// testKeyManager 对任意 KeyManager 运行同一组用例，prefix 避免与已有密钥重名
+func testKeyManager(t *testing.T, km KeyManager, prefix string) {
+	ctx := context.Background()
+	encName, signName := prefix+"enc", prefix+"sign"
+
+	if err := km.CreateKey(ctx, encName, KeyAES256GCM); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// 重复创建不报错
+	if err := km.CreateKey(ctx, encName, KeyAES256GCM); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	plaintext, aad := []byte("hello kms"), []byte("context")
+	ct1, err := km.Encrypt(ctx, encName, plaintext, aad)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.HasPrefix(ct1, "vault:v1:") {
+		t.Fatalf("bad: %s", ct1)
+	}
+
+	out, err := km.Decrypt(ctx, encName, ct1, aad)
+	if err != nil || string(out) != string(plaintext) {
+		t.Fatalf("bad: %q %v", out, err)
+	}
+	if _, err := km.Decrypt(ctx, encName, ct1, []byte("other context")); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	if err := km.Rotate(ctx, encName); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ct2, err := km.Encrypt(ctx, encName, plaintext, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.HasPrefix(ct2, "vault:v2:") {
+		t.Fatalf("bad: %s", ct2)
+	}
+
+	// 轮换后旧密文仍可解密
+	for _, c := range []struct {
+		ct  string
+		aad []byte
+	}{{ct1, aad}, {ct2, nil}} {
+		if out, err := km.Decrypt(ctx, encName, c.ct, c.aad); err != nil || string(out) != string(plaintext) {
+			t.Fatalf("bad: %q %v", out, err)
+		}
+	}
+
+	versions, err := km.Versions(ctx, encName)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 1 || versions[1].Version != 2 || versions[0].CreatedAt.IsZero() {
+		t.Fatalf("bad: %+v", versions)
+	}
+
+	if err := km.CreateKey(ctx, signName, KeyEd25519); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	msg := []byte("sign me")
+	sig, err := km.Sign(ctx, signName, msg)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := km.Verify(ctx, signName, msg, sig); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := km.Verify(ctx, signName, []byte("tampered"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+
+	versions, err = km.Versions(ctx, signName)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(versions) != 1 || versions[0].PublicKey == "" {
+		t.Fatalf("bad: %+v", versions)
+	}
+
+	if _, err := km.Versions(ctx, prefix+"missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expect ErrKeyNotFound, got %v", err)
+	}
+	if _, err := km.Decrypt(ctx, prefix+"missing", ct1, nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expect ErrKeyNotFound, got %v", err)
+	}
+
+	// 加密时不存在的密钥会被自动创建
+	upsert, err := km.Encrypt(ctx, prefix+"upsert", plaintext, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out, err := km.Decrypt(ctx, prefix+"upsert", upsert, nil); err != nil || string(out) != string(plaintext) {
+		t.Fatalf("bad: %q %v", out, err)
+	}
+
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := km.Encrypt(ctx, name, plaintext, nil); !errors.Is(err, ErrInvalidKeyName) {
+			t.Fatalf("%q: expect ErrInvalidKeyName, got %v", name, err)
+		}
+	}
+}
+
+func TestVaultTransit(t *testing.T) {
+	testKeyManager(t, newTestTransit(t), "")
+}
+
+// TestVaultTransit_server 在设置了 VAULT_ADDR 和 VAULT_TOKEN 时针对真实的
+// Vault（如 vault server -dev 并启用 transit 引擎）运行同一组用例
+func TestVaultTransit_server(t *testing.T) {
+	if os.Getenv("VAULT_ADDR") == "" || os.Getenv("VAULT_TOKEN") == "" {
+		t.Skip("VAULT_ADDR and VAULT_TOKEN not set")
+	}
+
+	km, err := NewVaultTransit(VaultConfigFromEnv())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	testKeyManager(t, km, fmt.Sprintf("kms-test-%d-", time.Now().UnixNano()))
+}
+
+func TestVaultTransit_errors(t *testing.T) {
+	srv := newFakeVault(t)
+
+	km, err := NewVaultTransit(&VaultConfig{Address: srv.URL, Token: "wrong"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var vaultErr *VaultError
+	if err := km.Ping(context.Background()); !errors.As(err, &vaultErr) || vaultErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expect permission denied, got %v", err)
+	}
+	if err := newTestTransit(t).Ping(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	err = km.CreateKey(context.Background(), "key", KeyAES256GCM)
+	if !errors.As(err, &vaultErr) || vaultErr.StatusCode != http.StatusForbidden || vaultErr.Errors[0] != "permission denied" {
+		t.Fatalf("expect permission denied, got %v", err)
+	}
+
+	// 代理返回的非 JSON 错误页保留原文
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>502 Bad Gateway</html>", http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	km, err = NewVaultTransit(&VaultConfig{Address: proxy.URL, Token: testToken})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	err = km.Ping(context.Background())
+	if !errors.As(err, &vaultErr) || vaultErr.StatusCode != http.StatusBadGateway ||
+		len(vaultErr.Errors) != 1 || vaultErr.Errors[0] != "<html>502 Bad Gateway</html>" {
+		t.Fatalf("expect bad gateway, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := newTestTransit(t).Versions(ctx, "key"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect context.Canceled, got %v", err)
+	}
+
+	for _, cfg := range []*VaultConfig{
+		nil,
+		{Address: srv.URL},
+		{Token: testToken},
+		{Address: "127.0.0.1:8200", Token: testToken},
+		{Address: "ftp://127.0.0.1", Token: testToken},
+	} {
+		if _, err := NewVaultTransit(cfg); !errors.Is(err, ErrInvalidVaultConfig) {
+			t.Fatalf("%+v: expect ErrInvalidVaultConfig, got %v", cfg, err)
+		}
+	}
+}
+
+func TestVaultTransit_mount(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path + " " + r.Header.Get("X-Vault-Namespace")
+		writeVault(w, http.StatusNoContent, nil)
+	}))
+	defer srv.Close()
+
+	km, err := NewVaultTransit(&VaultConfig{Address: srv.URL + "/", Token: testToken, Namespace: "team", Mount: "/kms/transit/"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := km.Rotate(context.Background(), "my key"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if expect := "/v1/kms/transit/keys/my key/rotate team"; got != expect {
+		t.Fatalf("expect %q, got %q", expect, got)
+	}
+}
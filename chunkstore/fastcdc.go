@@ -0,0 +1,164 @@
+package chunkstore
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+const (
+	DefaultMinSize = 16 * 1024
+	DefaultAvgSize = 64 * 1024
+	DefaultMaxSize = 256 * 1024
+
+	// normalization level 2
+	normalization = 2
+)
+
+var ErrInvalidChunkerOptions = errors.New("invalid chunker options")
+
+// ChunkerOptions 控制分块大小，为 nil 或字段为零值时使用对应的默认值
+type ChunkerOptions struct {
+	MinSize int
+	AvgSize int
+	MaxSize int
+}
+
+// gear 为固定的随机表，改变它会改变所有分块边界
+var gear = func() (table [256]uint64) {
+	// splitmix64
+	x := uint64(0x476f546f6f6c7343)
+	for i := range table {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return
+}()
+
+// Chunker 实现 FastCDC 内容定义分块：使用 gear 滚动哈希寻找切分点，
+// 在平均大小之前使用更严格的掩码、之后使用更宽松的掩码（normalized chunking），
+// 使分块大小集中在 AvgSize 附近。插入或删除数据只影响附近的分块，
+// 因此重复导出的文件大部分分块可以去重
+type Chunker struct {
+	r   io.Reader
+	buf []byte
+
+	start, end int
+	eof        bool
+
+	minSize, avgSize, maxSize int
+	maskS, maskL              uint64
+}
+
+func NewChunker(r io.Reader, opts *ChunkerOptions) (*Chunker, error) {
+	o := ChunkerOptions{DefaultMinSize, DefaultAvgSize, DefaultMaxSize}
+	if opts != nil {
+		if opts.MinSize != 0 {
+			o.MinSize = opts.MinSize
+		}
+		if opts.AvgSize != 0 {
+			o.AvgSize = opts.AvgSize
+		}
+		if opts.MaxSize != 0 {
+			o.MaxSize = opts.MaxSize
+		}
+	}
+	if o.MinSize <= 0 || o.MinSize > o.AvgSize || o.AvgSize > o.MaxSize ||
+		o.AvgSize&(o.AvgSize-1) != 0 {
+		return nil, ErrInvalidChunkerOptions
+	}
+
+	avgBits := bits.TrailingZeros(uint(o.AvgSize))
+	if avgBits <= normalization {
+		return nil, ErrInvalidChunkerOptions
+	}
+
+	return &Chunker{
+		r:       r,
+		buf:     make([]byte, 2*o.MaxSize),
+		minSize: o.MinSize,
+		avgSize: o.AvgSize,
+		maxSize: o.MaxSize,
+		maskS:   highMask(avgBits + normalization),
+		maskL:   highMask(avgBits - normalization),
+	}, nil
+}
+
+// Next 返回下一个数据块，数据结束时返回 io.EOF。
+// 返回的切片在下一次调用 Next 之前有效
+func (c *Chunker) Next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	n := c.cut(c.buf[c.start:c.end])
+	chunk := c.buf[c.start : c.start+n]
+	c.start += n
+
+	return chunk, nil
+}
+
+// fill 保证缓冲区中至少有 maxSize 字节，或已读到数据末尾
+func (c *Chunker) fill() error {
+	if c.eof || c.end-c.start >= c.maxSize {
+		return nil
+	}
+
+	copy(c.buf, c.buf[c.start:c.end])
+	c.end -= c.start
+	c.start = 0
+
+	for c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.minSize {
+		return n
+	}
+	if n > c.maxSize {
+		n = c.maxSize
+	}
+
+	normal := min(c.avgSize, n)
+
+	var h uint64
+	i := c.minSize
+	for ; i < normal; i++ {
+		h = (h << 1) + gear[data[i]]
+		if h&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		h = (h << 1) + gear[data[i]]
+		if h&c.maskL == 0 {
+			return i + 1
+		}
+	}
+
+	return n
+}
+
+// highMask 返回高位的 n 个 1，gear 哈希的高位由最近 64 个字节共同决定
+func highMask(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
@@ -0,0 +1,112 @@
+package chunkstore
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func randomData(seed int64, size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func chunkAll(t *testing.T, data []byte, opts *ChunkerOptions) [][]byte {
+	c, err := NewChunker(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var out [][]byte
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		out = append(out, append([]byte{}, chunk...))
+	}
+}
+
+func TestChunker(t *testing.T) {
+	data := randomData(1, 4<<20)
+	chunks := chunkAll(t, data, nil)
+
+	if !bytes.Equal(bytes.Join(chunks, nil), data) {
+		t.Fatalf("chunks do not reassemble the input")
+	}
+
+	for i, c := range chunks {
+		if len(c) > DefaultMaxSize {
+			t.Fatalf("chunk %d too large: %d", i, len(c))
+		}
+		if len(c) < DefaultMinSize && i != len(chunks)-1 {
+			t.Fatalf("chunk %d too small: %d", i, len(c))
+		}
+	}
+
+	avg := len(data) / len(chunks)
+	if avg < DefaultAvgSize/2 || avg > DefaultAvgSize*2 {
+		t.Fatalf("average chunk size %d far from %d", avg, DefaultAvgSize)
+	}
+}
+
+func TestChunker_shiftResistant(t *testing.T) {
+	data := randomData(2, 2<<20)
+	shifted := append([]byte("inserted prefix"), data...)
+
+	seen := map[string]bool{}
+	for _, c := range chunkAll(t, data, nil) {
+		seen[string(c)] = true
+	}
+
+	shared := 0
+	chunks := chunkAll(t, shifted, nil)
+	for _, c := range chunks {
+		if seen[string(c)] {
+			shared++
+		}
+	}
+
+	// Only the chunks around the insertion point may change
+	if shared < len(chunks)-2 {
+		t.Fatalf("only %d of %d chunks survived a prefix insertion", shared, len(chunks))
+	}
+}
+
+func TestChunker_invalid(t *testing.T) {
+	bad := []ChunkerOptions{
+		{MinSize: -1, AvgSize: 1024, MaxSize: 4096},
+		{MinSize: 512, AvgSize: -1024, MaxSize: 4096},
+		{MinSize: 2048, AvgSize: 1024, MaxSize: 4096},
+		{MinSize: 512, AvgSize: 1000, MaxSize: 4096},
+		{MinSize: 512, AvgSize: 1024, MaxSize: 1000},
+	}
+
+	for _, opts := range bad {
+		if _, err := NewChunker(bytes.NewReader(nil), &opts); err != ErrInvalidChunkerOptions {
+			t.Fatalf("%+v: expect ErrInvalidChunkerOptions, got %v", opts, err)
+		}
+	}
+
+	// Zero fields take the defaults
+	c, err := NewChunker(bytes.NewReader(nil), &ChunkerOptions{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if c.minSize != DefaultMinSize || c.avgSize != DefaultAvgSize || c.maxSize != DefaultMaxSize {
+		t.Fatalf("bad: %d %d %d", c.minSize, c.avgSize, c.maxSize)
+	}
+
+	c, err = NewChunker(bytes.NewReader(nil), &ChunkerOptions{AvgSize: 32 * 1024})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if c.minSize != DefaultMinSize || c.avgSize != 32*1024 || c.maxSize != DefaultMaxSize {
+		t.Fatalf("bad: %d %d %d", c.minSize, c.avgSize, c.maxSize)
+	}
+}
@@ -0,0 +1,276 @@
+// Package chunkstore 将数据按内容分块后以 BLAKE2 摘要为地址保存在本地文件系统，
+// 相同的分块只保存一次，可选 AES / ChaCha20 静态加密
+package chunkstore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	blake2 "tools/crypto/BLAKE2"
+)
+
+const manifestVersion = 1
+
+var (
+	ErrChunkNotFound   = errors.New("chunk not found")
+	ErrChunkCorrupted  = errors.New("chunk corrupted")
+	ErrBlobCorrupted   = errors.New("blob digest mismatch")
+	ErrMissingIDKey    = errors.New("encrypted store requires an id key")
+	ErrInvalidManifest = errors.New("invalid manifest")
+)
+
+// Sealer 是静态加密的接口，*aes.AESKey 和 *chacha.ChaCha20 都满足该接口。
+// ad 为分块 ID，把密文绑定到它的地址上
+type Sealer interface {
+	Seal(msg, ad []byte) ([]byte, error)
+	Open(sealed, ad []byte) ([]byte, error)
+}
+
+type Options struct {
+	// Sealer 非空时分块加密保存
+	Sealer Sealer
+
+	// IDKey 为加密存储计算分块 ID 的 MAC 密钥（1 到 64 字节），
+	// 避免暴露明文分块的哈希；未加密的存储直接使用 BLAKE2b-256
+	IDKey []byte
+
+	Chunker *ChunkerOptions
+}
+
+type Store struct {
+	dir     string
+	sealer  Sealer
+	idKey   []byte
+	chunker *ChunkerOptions
+}
+
+// Manifest 记录重组一个 blob 所需的分块列表
+type Manifest struct {
+	Version int        `json:"version"`
+	Size    int64      `json:"size"`
+	Digest  string     `json:"digest"`
+	Chunks  []ChunkRef `json:"chunks"`
+}
+
+type ChunkRef struct {
+	ID   string `json:"id"`
+	Size int    `json:"size"`
+}
+
+// Open 打开（必要时创建）位于 dir 的分块存储
+func Open(dir string, opts *Options) (*Store, error) {
+	s := &Store{dir: dir}
+	if opts != nil {
+		s.sealer = opts.Sealer
+		s.idKey = append([]byte{}, opts.IDKey...)
+		s.chunker = opts.Chunker
+	}
+
+	if s.sealer != nil && len(s.idKey) == 0 {
+		return nil, ErrMissingIDKey
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "chunks"), 0o700); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Put 分块保存 r 中的数据，已存在的分块会被跳过
+func (s *Store) Put(r io.Reader) (*Manifest, error) {
+	chunker, err := NewChunker(r, s.chunker)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, _ := blake2.New(256, nil)
+	m := &Manifest{Version: manifestVersion}
+
+	for {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := s.chunkID(chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.putChunk(id, chunk); err != nil {
+			return nil, err
+		}
+
+		digest.Write(chunk)
+		m.Size += int64(len(chunk))
+		m.Chunks = append(m.Chunks, ChunkRef{ID: id, Size: len(chunk)})
+	}
+
+	m.Digest = hex.EncodeToString(digest.Sum(nil))
+	return m, nil
+}
+
+// Get 按 manifest 重组 blob 写入 w，每个分块和整体摘要都会校验。
+// 返回错误时已写入 w 的数据不可信
+func (s *Store) Get(m *Manifest, w io.Writer) error {
+	if m == nil || m.Version != manifestVersion {
+		return ErrInvalidManifest
+	}
+
+	digest, _ := blake2.New(256, nil)
+	var size int64
+
+	for _, ref := range m.Chunks {
+		chunk, err := s.getChunk(ref.ID)
+		if err != nil {
+			return err
+		}
+		if len(chunk) != ref.Size {
+			return fmt.Errorf("%w: %s", ErrChunkCorrupted, ref.ID)
+		}
+
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+
+		digest.Write(chunk)
+		size += int64(len(chunk))
+	}
+
+	if size != m.Size || hex.EncodeToString(digest.Sum(nil)) != m.Digest {
+		return ErrBlobCorrupted
+	}
+
+	return nil
+}
+
+// Has 判断分块是否已保存
+func (s *Store) Has(id string) bool {
+	path, err := s.chunkPath(id)
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func (s *Store) chunkID(chunk []byte) (string, error) {
+	if s.sealer == nil {
+		sum, err := blake2.Hash(chunk, 256)
+		return hex.EncodeToString(sum), err
+	}
+
+	mac, err := blake2.MAC(s.idKey, chunk, 256)
+	return hex.EncodeToString(mac), err
+}
+
+func (s *Store) chunkPath(id string) (string, error) {
+	raw, err := hex.DecodeString(id)
+	if err != nil || len(raw) != 32 {
+		return "", fmt.Errorf("%w: bad chunk id %q", ErrInvalidManifest, id)
+	}
+
+	return filepath.Join(s.dir, "chunks", id[:2], id), nil
+}
+
+func (s *Store) putChunk(id string, chunk []byte) error {
+	path, err := s.chunkPath(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	data := chunk
+	if s.sealer != nil {
+		if data, err = s.sealer.Seal(chunk, []byte(id)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// 先写临时文件再改名，避免进程中断留下不完整的分块
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *Store) getChunk(id string) ([]byte, error) {
+	path, err := s.chunkPath(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrChunkNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.sealer != nil {
+		if data, err = s.sealer.Open(data, []byte(id)); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrChunkCorrupted, id, err)
+		}
+	}
+
+	got, err := s.chunkID(data)
+	if err != nil {
+		return nil, err
+	}
+	if got != id {
+		return nil, fmt.Errorf("%w: %s", ErrChunkCorrupted, id)
+	}
+
+	return data, nil
+}
+
+// Marshal 将 manifest 编码为 JSON
+func (m *Manifest) Marshal() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// ParseManifest 解析 Marshal 的输出
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if m.Version != manifestVersion {
+		return nil, ErrInvalidManifest
+	}
+
+	return &m, nil
+}
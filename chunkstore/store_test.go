@@ -0,0 +1,123 @@
+package chunkstore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	aes "tools/crypto/AES"
+	"tools/crypto/chacha"
+)
+
+func countChunks(t *testing.T, dir string) int {
+	n := 0
+	filepath.WalkDir(filepath.Join(dir, "chunks"), func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+func TestStore(t *testing.T) {
+	aesKey, _ := aes.GenerateAESKey()
+	chachaKey := chacha.GenKey()
+
+	options := map[string]*Options{
+		"plain":  nil,
+		"aes":    {Sealer: aesKey, IDKey: []byte("store id key")},
+		"chacha": {Sealer: &chachaKey, IDKey: []byte("store id key")},
+	}
+
+	for name, opts := range options {
+		dir := t.TempDir()
+		store, err := Open(dir, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		data := randomData(3, 1<<20)
+
+		m, err := store.Put(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		stored := countChunks(t, dir)
+
+		// A second export with a small edit should mostly deduplicate
+		edited := append([]byte{}, data...)
+		copy(edited[len(edited)/2:], "edited")
+		if _, err := store.Put(bytes.NewReader(edited)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if added := countChunks(t, dir) - stored; added > 2 {
+			t.Fatalf("%s: edit added %d chunks", name, added)
+		}
+
+		blob, _ := m.Marshal()
+		parsed, err := ParseManifest(blob)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		var out bytes.Buffer
+		if err := store.Get(parsed, &out); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(out.Bytes(), data) {
+			t.Fatalf("%s: bad output", name)
+		}
+	}
+}
+
+func TestStore_corrupted(t *testing.T) {
+	key, _ := aes.GenerateAESKey()
+
+	for _, opts := range []*Options{nil, {Sealer: key, IDKey: []byte("id")}} {
+		dir := t.TempDir()
+		store, _ := Open(dir, opts)
+
+		m, err := store.Put(bytes.NewReader(randomData(4, 200*1024)))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		path, _ := store.chunkPath(m.Chunks[0].ID)
+		data, _ := os.ReadFile(path)
+		data[len(data)-1] ^= 0x01
+		os.WriteFile(path, data, 0o600)
+
+		if err := store.Get(m, &bytes.Buffer{}); !errors.Is(err, ErrChunkCorrupted) {
+			t.Fatalf("expect ErrChunkCorrupted, got %v", err)
+		}
+
+		os.Remove(path)
+		if err := store.Get(m, &bytes.Buffer{}); !errors.Is(err, ErrChunkNotFound) {
+			t.Fatalf("expect ErrChunkNotFound, got %v", err)
+		}
+	}
+}
+
+func TestStore_manifest(t *testing.T) {
+	store, _ := Open(t.TempDir(), nil)
+
+	m, _ := store.Put(bytes.NewReader(randomData(5, 300*1024)))
+
+	// Dropping a chunk keeps every chunk valid but breaks the blob digest
+	m.Chunks = m.Chunks[1:]
+	if err := store.Get(m, &bytes.Buffer{}); !errors.Is(err, ErrBlobCorrupted) {
+		t.Fatalf("expect ErrBlobCorrupted, got %v", err)
+	}
+
+	m.Chunks[0].ID = "../../etc/passwd"
+	if err := store.Get(m, &bytes.Buffer{}); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expect ErrInvalidManifest, got %v", err)
+	}
+
+	if _, err := Open(t.TempDir(), &Options{Sealer: &chacha.ChaCha20{}}); !errors.Is(err, ErrMissingIDKey) {
+		t.Fatalf("expect ErrMissingIDKey, got %v", err)
+	}
+}
@@ -3,10 +3,7 @@ package aes
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"errors"
-	"fmt"
-	"io"
 )
 
 type AESKey struct {
@@ -16,8 +13,18 @@ type AESKey struct {
 var (
 	ErrGenKeyInputError = errors.New("input text length must more than 32 bytes")
 	ErrWrongKeyBytes    = errors.New("key bytes must length 32")
+
+	// ErrInvalidCipherText 为旧 CBC 密文长度或填充错误，两者不作区分，
+	// 避免成为填充预言机
+	ErrInvalidCipherText = errors.New("invalid cbc cipher text")
+
+	// Deprecated: 与 ErrInvalidCipherText 相同
+	ErrInvalidPadding = ErrInvalidCipherText
 )
 
+// GenAESkey 直接截取输入的前 32 字节作为密钥，不做任何派生。
+//
+// Deprecated: 口令请使用 DeriveAESKey，随机密钥请使用 GenerateAESKey
 func GenAESkey(in []byte) (*AESKey, error) {
 	if len(in) < 32 {
 		return nil, ErrGenKeyInputError
@@ -34,52 +41,56 @@ func (c *AESKey) StringKey() string {
 	return string(c.private)
 }
 
+// Encrypt 等同于没有附加数据的 Seal，输出带版本头并经过认证
 func (c *AESKey) Encrypt(msg []byte) ([]byte, error) {
-	block, _ := aes.NewCipher(c.private)
+	return c.Seal(msg, nil)
+}
 
-	plaintextBytes := []byte(msg)
-	plaintextBytes = pkcs5Padding(plaintextBytes, aes.BlockSize)
+// Decrypt 解密 Encrypt 或 Seal（无附加数据）产生的密文。版本或模式不支持时
+// 返回 ErrUnsupportedVersion 或 ErrUnsupportedMode，被篡改的密文返回 ErrAuthFailed，
+// 不会回退到 CBC。旧版 Encrypt 产生的 CBC 密文需显式调用 DecryptCBC
+func (c *AESKey) Decrypt(cipherText []byte) ([]byte, error) {
+	return c.Open(cipherText, nil)
+}
 
-	cipherText := make([]byte, aes.BlockSize+len(plaintextBytes))
-	iv := cipherText[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+// DecryptCBC 解密旧版 Encrypt 产生的 AES-256-CBC 密文（iv || ciphertext）。
+//
+// Deprecated: CBC 没有完整性校验，篡改的密文可能解密出错误的明文。
+// 旧数据解密后请用 Seal 重新加密
+func (c *AESKey) DecryptCBC(cipherText []byte) ([]byte, error) {
+	block, err := aes.NewCipher(c.private)
+	if err != nil {
 		return nil, err
 	}
 
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(cipherText[aes.BlockSize:], plaintextBytes)
-
-	return cipherText, nil
-}
-
-func (c *AESKey) Decrypt(cipherText []byte) ([]byte, error) {
-	block, _ := aes.NewCipher(c.private)
-
-	if len(cipherText) < aes.BlockSize {
-		return nil, fmt.Errorf("cipher text too short")
+	if len(cipherText) < 2*aes.BlockSize || len(cipherText)%aes.BlockSize != 0 {
+		return nil, ErrInvalidCipherText
 	}
 
 	iv := cipherText[:aes.BlockSize]
-	cipherText = cipherText[aes.BlockSize:]
+	plaintext := make([]byte, len(cipherText)-aes.BlockSize)
 
 	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(cipherText, cipherText)
+	mode.CryptBlocks(plaintext, cipherText[aes.BlockSize:])
 
-	plaintext := pkcs5UnPadding(cipherText)
-	return plaintext, nil
+	return pkcs5UnPadding(plaintext)
 }
 
-func pkcs5Padding(data []byte, blockSize int) []byte {
-	padding := blockSize - len(data)%blockSize
-	padText := make([]byte, padding)
-	for i := 0; i < padding; i++ {
-		padText[i] = byte(padding)
+func pkcs5UnPadding(data []byte) ([]byte, error) {
+	length := len(data)
+	if length == 0 {
+		return nil, ErrInvalidCipherText
 	}
-	return append(data, padText...)
-}
 
-func pkcs5UnPadding(data []byte) []byte {
-	length := len(data)
 	unpadding := int(data[length-1])
-	return data[:(length - unpadding)]
+	if unpadding == 0 || unpadding > aes.BlockSize || unpadding > length {
+		return nil, ErrInvalidCipherText
+	}
+	for _, b := range data[length-unpadding:] {
+		if int(b) != unpadding {
+			return nil, ErrInvalidCipherText
+		}
+	}
+
+	return data[:(length - unpadding)], nil
 }
@@ -1,7 +1,12 @@
 package aes
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"testing"
 )
 
@@ -21,3 +26,109 @@ func TestAES(t *testing.T) {
 
 	t.Logf("解密结果：%s", de)
 }
+
+// encryptCBC produces the iv || ciphertext format of the old Encrypt
+func encryptCBC(t *testing.T, key *AESKey, msg []byte) []byte {
+	block, err := aes.NewCipher(key.private)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	padding := aes.BlockSize - len(msg)%aes.BlockSize
+	padded := append(append([]byte{}, msg...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	out := make([]byte, aes.BlockSize+len(padded))
+	rand.Read(out[:aes.BlockSize])
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], padded)
+	return out
+}
+
+func TestDecrypt_invalid(t *testing.T) {
+	keys, _ := GenAESkey([]byte("197tabkldhf891gfbipASDOFY01GFBasdhfp891t2gf8ashdfhp"))
+
+	sealed, err := keys.Encrypt([]byte("test"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if sealed[0] != sealVersion || Mode(sealed[1]) != ModeGCM {
+		t.Fatalf("expect versioned header, got %x", sealed[:2])
+	}
+
+	// Any modified byte is refused, including the version and mode
+	for i := 0; i < len(sealed); i++ {
+		tampered := append([]byte{}, sealed...)
+		tampered[i] ^= 0x01
+
+		_, err := keys.Decrypt(tampered)
+		switch i {
+		case 0:
+			if !errors.Is(err, ErrUnsupportedVersion) {
+				t.Fatalf("byte %d: expect ErrUnsupportedVersion, got %v", i, err)
+			}
+		case 1:
+			if !errors.Is(err, ErrUnsupportedMode) {
+				t.Fatalf("byte %d: expect ErrUnsupportedMode, got %v", i, err)
+			}
+		default:
+			if !errors.Is(err, ErrAuthFailed) {
+				t.Fatalf("byte %d: expect ErrAuthFailed, got %v", i, err)
+			}
+		}
+	}
+	for _, b := range []byte{0x00, 0x02, 0xff} {
+		tampered := append([]byte{}, sealed...)
+		tampered[0] = b
+		if _, err := keys.Decrypt(tampered); err == nil {
+			t.Fatalf("version %#x: expect error", b)
+		}
+	}
+	if _, err := keys.Decrypt(sealed[:headerSize+4]); !errors.Is(err, ErrCipherTextShort) {
+		t.Fatalf("expect ErrCipherTextShort, got %v", err)
+	}
+	if _, err := keys.Decrypt(nil); !errors.Is(err, ErrCipherTextShort) {
+		t.Fatalf("expect ErrCipherTextShort, got %v", err)
+	}
+
+	// Legacy CBC cipher texts only decrypt through DecryptCBC
+	legacy := encryptCBC(t, keys, []byte("legacy"))
+	if out, err := keys.DecryptCBC(legacy); err != nil || string(out) != "legacy" {
+		t.Fatalf("bad: %q %v", out, err)
+	}
+	if _, err := keys.Decrypt(legacy); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	// Length and padding failures are the same error
+	for _, bad := range [][]byte{legacy[:len(legacy)-1], legacy[:16], nil} {
+		if _, err := keys.DecryptCBC(bad); !errors.Is(err, ErrInvalidCipherText) {
+			t.Fatalf("expect ErrInvalidCipherText, got %v", err)
+		}
+	}
+
+	padding := append([]byte{}, legacy...)
+	padding[len(padding)-17] ^= 0x01
+	if _, err := keys.DecryptCBC(padding); !errors.Is(err, ErrInvalidCipherText) {
+		t.Fatalf("expect ErrInvalidCipherText, got %v", err)
+	}
+}
+
+func TestPKCS5UnPadding_invalid(t *testing.T) {
+	bad := [][]byte{
+		nil,
+		{1, 2, 3, 0},
+		{1, 2, 3, 17},
+		{1, 2, 3, 5},
+		{1, 2, 2, 3},
+	}
+
+	for _, in := range bad {
+		if _, err := pkcs5UnPadding(in); err != ErrInvalidCipherText {
+			t.Fatalf("%v: expect ErrInvalidCipherText, got %v", in, err)
+		}
+	}
+
+	out, err := pkcs5UnPadding([]byte{1, 2, 2, 2})
+	if err != nil || len(out) != 2 {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+}
@@ -0,0 +1,105 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// Mode 记录在密文头部，标识密文使用的 AEAD 模式
+type Mode byte
+
+const (
+	ModeGCM Mode = 0x01
+)
+
+const (
+	// sealVersion 是 Seal 输出格式的版本号
+	sealVersion = 0x01
+
+	// headerSize 是密文头部长度：version(1) || mode(1)
+	headerSize = 2
+)
+
+var (
+	ErrAuthFailed         = errors.New("message authentication failed")
+	ErrUnsupportedVersion = errors.New("unsupported cipher text version")
+	ErrUnsupportedMode    = errors.New("unsupported cipher text mode")
+	ErrCipherTextShort    = errors.New("cipher text too short")
+)
+
+// Seal 使用 AES-256-GCM 加密并认证 msg 与附加数据 ad。
+// 输出格式为 version || mode || nonce || ciphertext || tag，
+// 头部同样作为附加数据参与认证，篡改任意字节都会导致 Open 失败
+func (c *AESKey) Seal(msg, ad []byte) ([]byte, error) {
+	aead, err := c.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, headerSize+aead.NonceSize(), headerSize+aead.NonceSize()+len(msg)+aead.Overhead())
+	out[0] = sealVersion
+	out[1] = byte(ModeGCM)
+
+	nonce := out[headerSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, nonce, msg, additionalData(out[:headerSize], ad)), nil
+}
+
+// Open 解密 Seal 产生的密文，ad 必须与加密时一致。
+// 密文或附加数据被篡改时返回 ErrAuthFailed
+func (c *AESKey) Open(cipherText, ad []byte) ([]byte, error) {
+	if len(cipherText) < headerSize {
+		return nil, ErrCipherTextShort
+	}
+	if cipherText[0] != sealVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if Mode(cipherText[1]) != ModeGCM {
+		return nil, ErrUnsupportedMode
+	}
+
+	aead, err := c.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cipherText) < headerSize+aead.NonceSize()+aead.Overhead() {
+		return nil, ErrCipherTextShort
+	}
+
+	nonce := cipherText[headerSize : headerSize+aead.NonceSize()]
+	body := cipherText[headerSize+aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, body, additionalData(cipherText[:headerSize], ad))
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+
+	return plaintext, nil
+}
+
+func (c *AESKey) gcm() (cipher.AEAD, error) {
+	return newGCM(c.private)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData 将密文头部绑定到调用方提供的附加数据之前
+func additionalData(header, ad []byte) []byte {
+	out := make([]byte, 0, len(header)+len(ad))
+	out = append(out, header...)
+	return append(out, ad...)
+}
@@ -0,0 +1,79 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	keys, _ := GenAESkey([]byte("197tabkldhf891gfbipASDOFY01GFBasdhfp891t2gf8ashdfhp"))
+
+	msg := []byte("韩媒发现：“抢枪”女子身份不简单")
+	ad := []byte("user-42")
+
+	sealed, err := keys.Seal(msg, ad)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if sealed[0] != sealVersion || Mode(sealed[1]) != ModeGCM {
+		t.Fatalf("bad header: %x", sealed[:headerSize])
+	}
+
+	out, err := keys.Open(sealed, ad)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !bytes.Equal(out, msg) {
+		t.Fatalf("bad: %s", out)
+	}
+}
+
+func TestOpen_tampered(t *testing.T) {
+	keys, _ := GenAESkey([]byte("197tabkldhf891gfbipASDOFY01GFBasdhfp891t2gf8ashdfhp"))
+
+	sealed, err := keys.Seal([]byte("test"), []byte("ad"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Flip every byte after the header once
+	for i := headerSize; i < len(sealed); i++ {
+		tampered := append([]byte{}, sealed...)
+		tampered[i] ^= 0x01
+
+		if _, err := keys.Open(tampered, []byte("ad")); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("byte %d: expect ErrAuthFailed, got %v", i, err)
+		}
+	}
+
+	// Wrong associated data
+	if _, err := keys.Open(sealed, []byte("other")); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expect ErrAuthFailed, got %v", err)
+	}
+
+	// Truncated
+	if _, err := keys.Open(sealed[:headerSize+4], []byte("ad")); !errors.Is(err, ErrCipherTextShort) {
+		t.Fatalf("expect ErrCipherTextShort, got %v", err)
+	}
+}
+
+func TestOpen_header(t *testing.T) {
+	keys, _ := GenAESkey([]byte("197tabkldhf891gfbipASDOFY01GFBasdhfp891t2gf8ashdfhp"))
+
+	sealed, _ := keys.Seal([]byte("test"), nil)
+
+	badVersion := append([]byte{}, sealed...)
+	badVersion[0] = 0xff
+	if _, err := keys.Open(badVersion, nil); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expect ErrUnsupportedVersion, got %v", err)
+	}
+
+	badMode := append([]byte{}, sealed...)
+	badMode[1] = 0xff
+	if _, err := keys.Open(badMode, nil); !errors.Is(err, ErrUnsupportedMode) {
+		t.Fatalf("expect ErrUnsupportedMode, got %v", err)
+	}
+}
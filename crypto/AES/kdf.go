@@ -0,0 +1,39 @@
+package aes
+
+import (
+	"crypto/rand"
+	"io"
+
+	"tools/crypto/kdf"
+)
+
+const keySize = 32
+
+// DeriveAESKey 使用口令和 kdf 参数派生 AES-256 密钥
+func DeriveAESKey(passphrase []byte, p *kdf.Params) (*AESKey, error) {
+	key, err := kdf.DeriveKey(passphrase, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESKey{private: key}, nil
+}
+
+// GenerateAESKey 从 crypto/rand 生成随机的 AES-256 密钥
+func GenerateAESKey() (*AESKey, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return &AESKey{private: key}, nil
+}
+
+// NewAESKey 使用 32 字节的原始密钥构造 AESKey
+func NewAESKey(key []byte) (*AESKey, error) {
+	if len(key) != keySize {
+		return nil, ErrWrongKeyBytes
+	}
+
+	return &AESKey{private: append([]byte{}, key...)}, nil
+}
@@ -0,0 +1,69 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"tools/crypto/kdf"
+)
+
+func TestDeriveAESKey(t *testing.T) {
+	for _, k := range []kdf.KDF{kdf.Argon2id, kdf.Scrypt, kdf.HKDF} {
+		params, err := kdf.NewParams(k)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Keep the tests fast
+		params.Memory, params.N = 1024, 1<<10
+
+		blob, err := params.Marshal()
+		if err != nil {
+			t.Fatalf("kdf %d: %v", k, err)
+		}
+
+		parsed, err := kdf.ParseParams(blob)
+		if err != nil {
+			t.Fatalf("kdf %d: %v", k, err)
+		}
+
+		k1, err := DeriveAESKey([]byte("correct horse battery staple"), params)
+		if err != nil {
+			t.Fatalf("kdf %d: %v", k, err)
+		}
+		k2, err := DeriveAESKey([]byte("correct horse battery staple"), parsed)
+		if err != nil {
+			t.Fatalf("kdf %d: %v", k, err)
+		}
+		if !bytes.Equal(k1.private, k2.private) {
+			t.Fatalf("kdf %d: bad: %x %x", k, k1.private, k2.private)
+		}
+
+		sealed, _ := k1.Seal([]byte("test"), blob)
+		if out, err := k2.Open(sealed, blob); err != nil || string(out) != "test" {
+			t.Fatalf("kdf %d: bad: %s %v", k, out, err)
+		}
+	}
+}
+
+func TestGenerateAESKey(t *testing.T) {
+	k1, err := GenerateAESKey()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	k2, _ := GenerateAESKey()
+
+	if len(k1.private) != 32 || bytes.Equal(k1.private, k2.private) {
+		t.Fatalf("bad: %x %x", k1.private, k2.private)
+	}
+
+	if _, err := NewAESKey(k1.private[:31]); !errors.Is(err, ErrWrongKeyBytes) {
+		t.Fatalf("expect ErrWrongKeyBytes, got %v", err)
+	}
+
+	k3, err := NewAESKey(k1.private)
+	if err != nil || !bytes.Equal(k3.private, k1.private) {
+		t.Fatalf("bad: %v", err)
+	}
+}
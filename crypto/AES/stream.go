@@ -0,0 +1,28 @@
+package aes
+
+import (
+	"io"
+
+	"tools/crypto/stream"
+)
+
+// EncryptStream 以 AES-256-GCM 分段加密 src 并写入 dst，适用于大文件
+func (c *AESKey) EncryptStream(dst io.Writer, src io.Reader) error {
+	return stream.Encrypt(dst, src, c.private, newGCM)
+}
+
+// DecryptStream 解密 EncryptStream 的输出。
+// 返回错误时已写入 dst 的明文不可信，必须丢弃
+func (c *AESKey) DecryptStream(dst io.Writer, src io.Reader) error {
+	return stream.Decrypt(dst, src, c.private, newGCM)
+}
+
+// NewEncryptWriter 返回加密写入器，写完后必须调用 Close
+func (c *AESKey) NewEncryptWriter(dst io.Writer) (io.WriteCloser, error) {
+	return stream.NewWriter(dst, c.private, newGCM)
+}
+
+// NewDecryptReader 返回解密读取器
+func (c *AESKey) NewDecryptReader(src io.Reader) (io.Reader, error) {
+	return stream.NewReader(src, c.private, newGCM)
+}
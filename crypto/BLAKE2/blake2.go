@@ -1,60 +1,135 @@
 package blake2
 
 import (
+	"crypto/subtle"
 	"errors"
-	"fmt"
+	"hash"
+	"io"
 
 	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
 )
 
-// blake2 是一个 Hash 算法
-func Hash(data []byte, bytes int) ([]byte, error) {
-	switch bytes {
-	case 384:
-		hash, err := blake2b.New384(nil)
-		if err != nil {
-			fmt.Println("Error creating hash:", err)
-			return nil, err
-		}
-
-		// 将数据写入哈希对象
-		hash.Write(data)
-
-		// 计算哈希值
-		result := hash.Sum(nil)
-		return result, nil
-
-	case 512:
-		hash, err := blake2b.New512(nil)
-		if err != nil {
-			fmt.Println("Error creating hash:", err)
-			return nil, err
-		}
-
-		// 将数据写入哈希对象
-		hash.Write(data)
-
-		// 计算哈希值
-		result := hash.Sum(nil)
-		return result, nil
+var (
+	ErrUndefinedHashBits = errors.New("undefined hash bytes")
+	ErrInvalidMACKey     = errors.New("blake2 mac key must be 1 to 64 bytes")
+	ErrInvalidLength     = errors.New("invalid blake2 output length")
+)
 
-	case 256:
-		hash, err := blake2b.New256(nil)
-		if err != nil {
-			fmt.Println("Error creating hash:", err)
-			return nil, err
-		}
+// blake2 是一个 Hash 算法，bits 为输出长度（256、384 或 512 位）
+func Hash(data []byte, bits int) ([]byte, error) {
+	hash, err := New(bits, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 将数据写入哈希对象
+	hash.Write(data)
+
+	// 计算哈希值
+	return hash.Sum(nil), nil
+}
+
+// New 返回流式的 BLAKE2b 哈希对象，key 非空时为带密钥的 MAC
+func New(bits int, key []byte) (hash.Hash, error) {
+	switch bits {
+	case 256, 384, 512:
+		return blake2b.New(bits/8, key)
+	default:
+		return nil, ErrUndefinedHashBits
+	}
+}
+
+// HashReader 流式计算 r 中全部数据的 BLAKE2b 哈希，不会把数据整体读入内存
+func HashReader(r io.Reader, bits int) ([]byte, error) {
+	hash, err := New(bits, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// MAC 使用带密钥的 BLAKE2b 计算消息认证码，key 为 1 到 64 字节
+func MAC(key, data []byte, bits int) ([]byte, error) {
+	if len(key) == 0 || len(key) > blake2b.Size {
+		return nil, ErrInvalidMACKey
+	}
+
+	hash, err := New(bits, key)
+	if err != nil {
+		return nil, err
+	}
 
-		// 将数据写入哈希对象
-		hash.Write(data)
+	hash.Write(data)
+	return hash.Sum(nil), nil
+}
+
+// VerifyMAC 以常数时间比较消息认证码，输出长度由 mac 的长度决定
+func VerifyMAC(key, data, mac []byte) bool {
+	expected, err := MAC(key, data, len(mac)*8)
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(expected, mac) == 1
+}
 
-		// 计算哈希值
-		result := hash.Sum(nil)
-		return result, nil
+// HashS 计算 BLAKE2s 哈希，bits 为 128 或 256。
+// BLAKE2s-128 只能作为 MAC 使用，因此必须提供 key
+func HashS(data []byte, bits int, key []byte) ([]byte, error) {
+	hash, err := NewS(bits, key)
+	if err != nil {
+		return nil, err
+	}
+
+	hash.Write(data)
+	return hash.Sum(nil), nil
+}
 
+// NewS 返回流式的 BLAKE2s 哈希对象
+func NewS(bits int, key []byte) (hash.Hash, error) {
+	switch bits {
+	case 128:
+		return blake2s.New128(key)
+	case 256:
+		return blake2s.New256(key)
 	default:
+		return nil, ErrUndefinedHashBits
+	}
+}
+
+// XOF 使用 BLAKE2Xb 输出任意长度（1 到 2^32-2 字节）的摘要
+func XOF(data []byte, length int, key []byte) ([]byte, error) {
+	if length <= 0 || int64(length) >= 1<<32-1 {
+		return nil, ErrInvalidLength
+	}
+
+	xof, err := blake2b.NewXOF(uint32(length), key)
+	if err != nil {
+		return nil, err
+	}
 
+	xof.Write(data)
+
+	out := make([]byte, length)
+	if _, err := io.ReadFull(xof, out); err != nil {
+		return nil, err
 	}
 
-	return nil, errors.New("undefined hash bytes")
+	return out, nil
+}
+
+// NewXOF 返回流式的 BLAKE2Xb，length 为 blake2b.OutputLengthUnknown 时输出长度不限
+func NewXOF(length uint32, key []byte) (blake2b.XOF, error) {
+	return blake2b.NewXOF(length, key)
+}
+
+// NewXOFS 返回流式的 BLAKE2Xs
+func NewXOFS(length uint16, key []byte) (blake2s.XOF, error) {
+	return blake2s.NewXOF(length, key)
 }
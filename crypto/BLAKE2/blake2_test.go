@@ -1,7 +1,9 @@
 package blake2
 
 import (
+	"bytes"
 	"encoding/base64"
+	"encoding/hex"
 	"testing"
 )
 
@@ -20,3 +22,104 @@ func TestHash(t *testing.T) {
 
 	t.Log(base64.RawStdEncoding.EncodeToString(hash))
 }
+
+func TestHash_invalid(t *testing.T) {
+	if _, err := Hash([]byte("test"), 32); err != ErrUndefinedHashBits {
+		t.Fatalf("expect ErrUndefinedHashBits, got %v", err)
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	input := bytes.Repeat([]byte("以下是一个在Go语言中使用BLAKE2b哈希算法的示例代码"), 10000)
+
+	exp, _ := Hash(input, 512)
+
+	out, err := HashReader(bytes.NewReader(input), 512)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !bytes.Equal(out, exp) {
+		t.Fatalf("bad: %x %x", out, exp)
+	}
+}
+
+func TestMAC(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	data := []byte("message")
+
+	mac, err := MAC(key, data, 256)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Keyed hashing must differ from the plain digest
+	plain, _ := Hash(data, 256)
+	if bytes.Equal(mac, plain) {
+		t.Fatalf("mac equals unkeyed hash")
+	}
+
+	if !VerifyMAC(key, data, mac) {
+		t.Fatalf("expect valid mac")
+	}
+
+	mac[0] ^= 0x01
+	if VerifyMAC(key, data, mac) {
+		t.Fatalf("expect invalid mac")
+	}
+
+	if VerifyMAC([]byte("other key"), data, mac) {
+		t.Fatalf("expect invalid mac")
+	}
+
+	if _, err := MAC(nil, data, 256); err != ErrInvalidMACKey {
+		t.Fatalf("expect ErrInvalidMACKey, got %v", err)
+	}
+}
+
+func TestHashS(t *testing.T) {
+	input := []byte("abc")
+
+	out, err := HashS(input, 256, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// RFC 7693 Appendix B
+	exp := "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982"
+	if hex.EncodeToString(out) != exp {
+		t.Fatalf("bad: %x", out)
+	}
+
+	if _, err := HashS(input, 128, nil); err == nil {
+		t.Fatalf("expect error for unkeyed BLAKE2s-128")
+	}
+
+	out, err = HashS(input, 128, []byte("0123456789abcdef"))
+	if err != nil || len(out) != 16 {
+		t.Fatalf("bad: %x %v", out, err)
+	}
+}
+
+func TestXOF(t *testing.T) {
+	input := []byte("abc")
+
+	for _, length := range []int{1, 32, 64, 65, 1000} {
+		out, err := XOF(input, length, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if len(out) != length {
+			t.Fatalf("bad length: %d", len(out))
+		}
+
+		again, _ := XOF(input, length, nil)
+		if !bytes.Equal(out, again) {
+			t.Fatalf("not deterministic")
+		}
+	}
+
+	if _, err := XOF(input, 0, nil); err != ErrInvalidLength {
+		t.Fatalf("expect ErrInvalidLength, got %v", err)
+	}
+}
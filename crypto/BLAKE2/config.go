@@ -0,0 +1,315 @@
+package blake2
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+// x/crypto 的 blake2b / blake2s 不支持参数块中的 salt 和 personalization，
+// 这里按 RFC 7693 实现了顺序模式下完整的参数块
+
+// Config 描述 BLAKE2 参数块，Size 为输出字节数
+type Config struct {
+	Size     int
+	Key      []byte
+	Salt     []byte
+	Personal []byte
+}
+
+const (
+	blockSizeB    = 128
+	saltSizeB     = 16
+	personalSizeB = 16
+
+	blockSizeS    = 64
+	saltSizeS     = 8
+	personalSizeS = 8
+)
+
+var ErrInvalidConfig = errors.New("invalid blake2 config")
+
+var ivB = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var ivS = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var sigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+// NewWithConfig 返回支持 salt 和 personalization 的 BLAKE2b 哈希对象。
+// Size 为 1 到 64 字节，Key 最长 64 字节，Salt 和 Personal 最长 16 字节（不足补零）
+func NewWithConfig(cfg *Config) (hash.Hash, error) {
+	if cfg.Size < 1 || cfg.Size > 64 || len(cfg.Key) > 64 ||
+		len(cfg.Salt) > saltSizeB || len(cfg.Personal) > personalSizeB {
+		return nil, ErrInvalidConfig
+	}
+
+	var param [64]byte
+	param[0] = byte(cfg.Size)
+	param[1] = byte(len(cfg.Key))
+	param[2] = 1 // fanout
+	param[3] = 1 // depth
+	copy(param[32:48], cfg.Salt)
+	copy(param[48:64], cfg.Personal)
+
+	d := &digestB{size: cfg.Size}
+	for i := range d.init {
+		d.init[i] = ivB[i] ^ binary.LittleEndian.Uint64(param[i*8:])
+	}
+	if len(cfg.Key) > 0 {
+		d.key = make([]byte, blockSizeB)
+		copy(d.key, cfg.Key)
+	}
+
+	d.Reset()
+	return d, nil
+}
+
+// NewSWithConfig 返回支持 salt 和 personalization 的 BLAKE2s 哈希对象。
+// Size 为 1 到 32 字节，Key 最长 32 字节，Salt 和 Personal 最长 8 字节（不足补零）
+func NewSWithConfig(cfg *Config) (hash.Hash, error) {
+	if cfg.Size < 1 || cfg.Size > 32 || len(cfg.Key) > 32 ||
+		len(cfg.Salt) > saltSizeS || len(cfg.Personal) > personalSizeS {
+		return nil, ErrInvalidConfig
+	}
+
+	var param [32]byte
+	param[0] = byte(cfg.Size)
+	param[1] = byte(len(cfg.Key))
+	param[2] = 1 // fanout
+	param[3] = 1 // depth
+	copy(param[16:24], cfg.Salt)
+	copy(param[24:32], cfg.Personal)
+
+	d := &digestS{size: cfg.Size}
+	for i := range d.init {
+		d.init[i] = ivS[i] ^ binary.LittleEndian.Uint32(param[i*4:])
+	}
+	if len(cfg.Key) > 0 {
+		d.key = make([]byte, blockSizeS)
+		copy(d.key, cfg.Key)
+	}
+
+	d.Reset()
+	return d, nil
+}
+
+type digestB struct {
+	h    [8]uint64
+	t    [2]uint64
+	init [8]uint64
+
+	block  [blockSizeB]byte
+	offset int
+
+	key  []byte
+	size int
+}
+
+func (d *digestB) Size() int      { return d.size }
+func (d *digestB) BlockSize() int { return blockSizeB }
+
+func (d *digestB) Reset() {
+	d.h = d.init
+	d.t = [2]uint64{}
+	d.offset = 0
+	if d.key != nil {
+		copy(d.block[:], d.key)
+		d.offset = blockSizeB
+	}
+}
+
+func (d *digestB) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// 最后一个块要留到 Sum 时带着结束标记压缩
+		if d.offset == blockSizeB {
+			d.compress(blockSizeB, false)
+			d.offset = 0
+		}
+
+		c := copy(d.block[d.offset:], p)
+		d.offset += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *digestB) Sum(in []byte) []byte {
+	dd := *d
+	clear(dd.block[dd.offset:])
+	dd.compress(dd.offset, true)
+
+	var out [64]byte
+	for i, v := range dd.h {
+		binary.LittleEndian.PutUint64(out[i*8:], v)
+	}
+	return append(in, out[:d.size]...)
+}
+
+func (d *digestB) compress(n int, last bool) {
+	d.t[0] += uint64(n)
+	if d.t[0] < uint64(n) {
+		d.t[1]++
+	}
+
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(d.block[i*8:])
+	}
+
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], ivB[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if last {
+		v[14] = ^v[14]
+	}
+
+	g := func(a, b, c, e int, x, y uint64) {
+		v[a] += v[b] + x
+		v[e] = bits.RotateLeft64(v[e]^v[a], -32)
+		v[c] += v[e]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+		v[a] += v[b] + y
+		v[e] = bits.RotateLeft64(v[e]^v[a], -16)
+		v[c] += v[e]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+	}
+
+	for r := 0; r < 12; r++ {
+		s := &sigma[r]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+type digestS struct {
+	h    [8]uint32
+	t    [2]uint32
+	init [8]uint32
+
+	block  [blockSizeS]byte
+	offset int
+
+	key  []byte
+	size int
+}
+
+func (d *digestS) Size() int      { return d.size }
+func (d *digestS) BlockSize() int { return blockSizeS }
+
+func (d *digestS) Reset() {
+	d.h = d.init
+	d.t = [2]uint32{}
+	d.offset = 0
+	if d.key != nil {
+		copy(d.block[:], d.key)
+		d.offset = blockSizeS
+	}
+}
+
+func (d *digestS) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if d.offset == blockSizeS {
+			d.compress(blockSizeS, false)
+			d.offset = 0
+		}
+
+		c := copy(d.block[d.offset:], p)
+		d.offset += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *digestS) Sum(in []byte) []byte {
+	dd := *d
+	clear(dd.block[dd.offset:])
+	dd.compress(dd.offset, true)
+
+	var out [32]byte
+	for i, v := range dd.h {
+		binary.LittleEndian.PutUint32(out[i*4:], v)
+	}
+	return append(in, out[:d.size]...)
+}
+
+func (d *digestS) compress(n int, last bool) {
+	d.t[0] += uint32(n)
+	if d.t[0] < uint32(n) {
+		d.t[1]++
+	}
+
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint32(d.block[i*4:])
+	}
+
+	var v [16]uint32
+	copy(v[:8], d.h[:])
+	copy(v[8:], ivS[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if last {
+		v[14] = ^v[14]
+	}
+
+	g := func(a, b, c, e int, x, y uint32) {
+		v[a] += v[b] + x
+		v[e] = bits.RotateLeft32(v[e]^v[a], -16)
+		v[c] += v[e]
+		v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+		v[a] += v[b] + y
+		v[e] = bits.RotateLeft32(v[e]^v[a], -8)
+		v[c] += v[e]
+		v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+	}
+
+	for r := 0; r < 10; r++ {
+		s := &sigma[r]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
@@ -0,0 +1,112 @@
+package blake2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+)
+
+func TestNewWithConfig(t *testing.T) {
+	// Vectors generated with Python's hashlib.blake2b / hashlib.blake2s
+	cases := []struct {
+		newHash func(*Config) (hash.Hash, error)
+		cfg     Config
+		input   []byte
+		exp     string
+	}{
+		{
+			newHash: NewWithConfig,
+			cfg:     Config{Size: 32, Salt: []byte("0123456789abcdef"), Personal: []byte("GoTools")},
+			input:   []byte("abc"),
+			exp:     "88e372c1b891072abeae5e65e67984e2c3846c24f117b34ebb3522b120266bba",
+		},
+		{
+			newHash: NewWithConfig,
+			cfg:     Config{Size: 64, Key: []byte("secret"), Salt: []byte("salty"), Personal: []byte("person")},
+			input:   bytes.Repeat([]byte("x"), 300),
+			exp:     "0059215865c9f9e5a127ef232857c14a0a5b0357bc8c639cc6a2d62ff38427f3e3538dd64d2be7f38dcdfdf50fde131dbf8c51484ac4a58705035ffc000e41ba",
+		},
+		{
+			newHash: NewSWithConfig,
+			cfg:     Config{Size: 32, Salt: []byte("saltsalt"), Personal: []byte("GoTools")},
+			input:   []byte("abc"),
+			exp:     "57f42c04cc2f1875b155aaae22b42998f14ef57f1488faa709b544c5e65e0106",
+		},
+		{
+			newHash: NewSWithConfig,
+			cfg:     Config{Size: 20, Key: []byte("secret"), Salt: []byte("salt"), Personal: []byte("pers")},
+			input:   bytes.Repeat([]byte("x"), 300),
+			exp:     "34d258908a2be00351d5746a0272ba712c6afc7b",
+		},
+	}
+
+	for i, c := range cases {
+		h, err := c.newHash(&c.cfg)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+
+		h.Write(c.input)
+		if out := hex.EncodeToString(h.Sum(nil)); out != c.exp {
+			t.Fatalf("case %d: bad: %s", i, out)
+		}
+	}
+}
+
+func TestNewWithConfig_matchesXCrypto(t *testing.T) {
+	key := make([]byte, 64)
+	rand.Read(key)
+
+	// Cover the block boundaries, with and without a key
+	for _, size := range []int{0, 1, 63, 64, 65, 127, 128, 129, 256, 1000} {
+		input := make([]byte, size)
+		rand.Read(input)
+
+		for _, k := range [][]byte{nil, key[:32]} {
+			ref, _ := blake2b.New512(k)
+			ref.Write(input)
+
+			h, _ := NewWithConfig(&Config{Size: 64, Key: k})
+			h.Write(input)
+
+			if !bytes.Equal(h.Sum(nil), ref.Sum(nil)) {
+				t.Fatalf("blake2b size %d key %d: mismatch", size, len(k))
+			}
+
+			refS, _ := blake2s.New256(k)
+			refS.Write(input)
+
+			hs, _ := NewSWithConfig(&Config{Size: 32, Key: k})
+			hs.Write(input)
+
+			if !bytes.Equal(hs.Sum(nil), refS.Sum(nil)) {
+				t.Fatalf("blake2s size %d key %d: mismatch", size, len(k))
+			}
+		}
+	}
+}
+
+func TestNewWithConfig_invalid(t *testing.T) {
+	bad := []Config{
+		{Size: 0},
+		{Size: 65},
+		{Size: 32, Salt: make([]byte, 17)},
+		{Size: 32, Personal: make([]byte, 17)},
+		{Size: 32, Key: make([]byte, 65)},
+	}
+
+	for _, cfg := range bad {
+		if _, err := NewWithConfig(&cfg); err != ErrInvalidConfig {
+			t.Fatalf("%+v: expect ErrInvalidConfig, got %v", cfg, err)
+		}
+	}
+
+	if _, err := NewSWithConfig(&Config{Size: 32, Salt: make([]byte, 9)}); err != ErrInvalidConfig {
+		t.Fatalf("expect ErrInvalidConfig, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package blake2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	DefaultTreeChunkSize = 1 << 20
+
+	treeLeafPrefix = 0x00
+	treeNodePrefix = 0x01
+	treeRootPrefix = 0x02
+)
+
+var (
+	ErrInvalidChunkSize = errors.New("invalid tree chunk size")
+	ErrChunkOutOfRange  = errors.New("chunk index out of range")
+)
+
+// TreeOptions 控制树哈希的分段大小和并发数，零值使用默认配置
+type TreeOptions struct {
+	ChunkSize int
+	Workers   int
+}
+
+// Tree 是分段的 Merkle 树哈希：数据按 ChunkSize 切分，每个分段计算叶子哈希，再两两合并到根。
+// 叶子和内部节点使用不同的前缀字节（同 RFC 6962），避免叶子被伪装成内部节点；
+// 某一层节点数为奇数时，最后一个节点直接提升到上一层。
+// 树的形状只取决于数据长度和 ChunkSize，因此结果与并发数无关。
+// 根为 H(0x02 || Size || ChunkSize || 顶层节点)，绑定了分段数量，
+// 证明无法把一个分段伪装成另一棵形状不同的树中的其他位置。
+// Tree 保存了所有层，可以为任意分段生成证明
+type Tree struct {
+	ChunkSize int
+	Size      int64
+
+	// levels[0] 为叶子层，最后一层只有根节点
+	levels [][][]byte
+}
+
+// Proof 证明第 Index 个分段属于某个根，Size 和 ChunkSize 决定树的形状，
+// 同样由根承诺
+type Proof struct {
+	Index     int
+	Size      int64
+	ChunkSize int
+	Siblings  [][]byte
+}
+
+// TreeHash 计算内存中数据的树哈希
+func TreeHash(data []byte, opts *TreeOptions) (*Tree, error) {
+	return TreeHashReaderAt(bytes.NewReader(data), int64(len(data)), opts)
+}
+
+// TreeHashReaderAt 使用多个 goroutine 并行计算 r 中前 size 字节的树哈希，
+// 适合直接传入 *os.File 处理大文件
+func TreeHashReaderAt(r io.ReaderAt, size int64, opts *TreeOptions) (*Tree, error) {
+	chunkSize, workers := DefaultTreeChunkSize, runtime.NumCPU()
+	if opts != nil {
+		if opts.ChunkSize != 0 {
+			chunkSize = opts.ChunkSize
+		}
+		if opts.Workers > 0 {
+			workers = opts.Workers
+		}
+	}
+	if chunkSize <= 0 || size < 0 {
+		return nil, ErrInvalidChunkSize
+	}
+
+	count := chunkCount(size, chunkSize)
+	workers = min(workers, count)
+
+	leaves := make([][]byte, count)
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// 输入比分段小时只分配 size 字节
+			buf := make([]byte, min(int64(chunkSize), size))
+			for i := range jobs {
+				off := int64(i) * int64(chunkSize)
+				n := int(min(int64(chunkSize), size-off))
+
+				// 读满时 ReadAt 可能同时返回 io.EOF；r 比 size 短时必须报错，
+				// 不能把 buf 中上一个分段的残留数据算进叶子
+				read, err := r.ReadAt(buf[:n], off)
+				if read == n {
+					err = nil
+				} else if err == nil || err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					// 继续消费任务，避免阻塞分发方
+					continue
+				}
+
+				leaves[i] = leafHash(buf[:n])
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	t := &Tree{
+		ChunkSize: chunkSize,
+		Size:      size,
+		levels:    [][][]byte{leaves},
+	}
+
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, (len(level)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(level) {
+				next[i] = nodeHash(level[2*i], level[2*i+1])
+			} else {
+				next[i] = level[2*i]
+			}
+		}
+
+		t.levels = append(t.levels, next)
+		level = next
+	}
+
+	return t, nil
+}
+
+// Root 返回树根
+func (t *Tree) Root() []byte {
+	return rootHash(t.Size, t.ChunkSize, t.levels[len(t.levels)-1][0])
+}
+
+// Chunks 返回分段数量
+func (t *Tree) Chunks() int {
+	return len(t.levels[0])
+}
+
+// Proof 生成第 index 个分段的证明
+func (t *Tree) Proof(index int) (*Proof, error) {
+	if index < 0 || index >= t.Chunks() {
+		return nil, ErrChunkOutOfRange
+	}
+
+	p := &Proof{
+		Index:     index,
+		Size:      t.Size,
+		ChunkSize: t.ChunkSize,
+	}
+
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			p.Siblings = append(p.Siblings, append([]byte{}, level[sibling]...))
+		}
+		index /= 2
+	}
+
+	return p, nil
+}
+
+// VerifyChunk 校验 chunk 是否为 root 对应数据中的第 proof.Index 个分段
+func VerifyChunk(root, chunk []byte, proof *Proof) bool {
+	if proof == nil || proof.ChunkSize <= 0 || proof.Size < 0 {
+		return false
+	}
+
+	leaves := chunkCount(proof.Size, proof.ChunkSize)
+	if proof.Index < 0 || proof.Index >= leaves {
+		return false
+	}
+
+	// 分段长度同样由 Size 决定，只有最后一个分段可以较短
+	off := int64(proof.Index) * int64(proof.ChunkSize)
+	if int64(len(chunk)) != min(int64(proof.ChunkSize), proof.Size-off) {
+		return false
+	}
+
+	h := leafHash(chunk)
+	index, width, siblings := proof.Index, leaves, proof.Siblings
+
+	for width > 1 {
+		switch {
+		case index%2 == 1:
+			if len(siblings) == 0 {
+				return false
+			}
+			h, siblings = nodeHash(siblings[0], h), siblings[1:]
+		case index+1 < width:
+			if len(siblings) == 0 {
+				return false
+			}
+			h, siblings = nodeHash(h, siblings[0]), siblings[1:]
+		}
+
+		index /= 2
+		width = (width + 1) / 2
+	}
+
+	return len(siblings) == 0 && bytes.Equal(rootHash(proof.Size, proof.ChunkSize, h), root)
+}
+
+// chunkCount 返回分段数量，空数据视为一个空分段
+func chunkCount(size int64, chunkSize int) int {
+	count := size / int64(chunkSize)
+	if size%int64(chunkSize) != 0 {
+		count++
+	}
+	return int(max(count, 1))
+}
+
+func leafHash(chunk []byte) []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{treeLeafPrefix})
+	h.Write(chunk)
+	return h.Sum(nil)
+}
+
+func rootHash(size int64, chunkSize int, top []byte) []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{treeRootPrefix})
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(size)))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(chunkSize)))
+	h.Write(top)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte{treeNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,176 @@
+package blake2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestTreeHash_deterministic(t *testing.T) {
+	data := make([]byte, 10*1024+123)
+	rand.Read(data)
+
+	var root []byte
+	for _, workers := range []int{1, 2, 3, 8, 64} {
+		tree, err := TreeHash(data, &TreeOptions{ChunkSize: 1024, Workers: workers})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		if tree.Chunks() != 11 {
+			t.Fatalf("bad chunk count: %d", tree.Chunks())
+		}
+
+		if root == nil {
+			root = tree.Root()
+		} else if !bytes.Equal(root, tree.Root()) {
+			t.Fatalf("workers %d: root differs", workers)
+		}
+	}
+
+	// Changing the chunk size changes the tree
+	other, _ := TreeHash(data, &TreeOptions{ChunkSize: 2048})
+	if bytes.Equal(root, other.Root()) {
+		t.Fatalf("expect different root")
+	}
+}
+
+func TestTreeHash_proofs(t *testing.T) {
+	for _, size := range []int{0, 1, 1024, 1025, 3 * 1024, 7*1024 + 1, 16 * 1024} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		tree, err := TreeHash(data, &TreeOptions{ChunkSize: 1024})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		root := tree.Root()
+
+		for i := 0; i < tree.Chunks(); i++ {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			chunk := data[i*1024 : min((i+1)*1024, size)]
+			if !VerifyChunk(root, chunk, proof) {
+				t.Fatalf("size %d chunk %d: expect valid proof", size, i)
+			}
+
+			if len(chunk) > 0 {
+				bad := append([]byte{}, chunk...)
+				bad[0] ^= 0x01
+				if VerifyChunk(root, bad, proof) {
+					t.Fatalf("size %d chunk %d: tampered chunk verified", size, i)
+				}
+			}
+
+			if tree.Chunks() > 1 {
+				moved := *proof
+				moved.Index = (proof.Index + 1) % tree.Chunks()
+				if VerifyChunk(root, chunk, &moved) {
+					t.Fatalf("size %d chunk %d: proof verified at wrong index", size, i)
+				}
+			}
+		}
+	}
+}
+
+func TestTreeHash_forgedShape(t *testing.T) {
+	data := make([]byte, 3*1024)
+	rand.Read(data)
+
+	tree, _ := TreeHash(data, &TreeOptions{ChunkSize: 1024})
+	proof, _ := tree.Proof(2)
+	chunk := data[2048:]
+
+	// In a 3 leaf tree the top node is H(N01, leaf(c2)), which is also the
+	// top node of the 2 leaf tree with c2 at index 1. Only the committed
+	// size tells them apart.
+	forged := *proof
+	forged.Index, forged.Size = 1, 2048
+	if VerifyChunk(tree.Root(), chunk, &forged) {
+		t.Fatalf("proof verified for a forged leaf count")
+	}
+
+	forged = *proof
+	forged.ChunkSize = 2048
+	if VerifyChunk(tree.Root(), chunk, &forged) {
+		t.Fatalf("proof verified for a forged chunk size")
+	}
+
+	// a short last chunk must have exactly the committed length
+	short, _ := TreeHash(data[:2500], &TreeOptions{ChunkSize: 1024})
+	proof, _ = short.Proof(2)
+	if VerifyChunk(short.Root(), append(data[2048:2500:2500], 0), proof) {
+		t.Fatalf("chunk with trailing byte verified")
+	}
+}
+
+// shortReaderAt reports a size larger than the data it can return
+type shortReaderAt struct {
+	data []byte
+}
+
+func (r shortReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestTreeHashReaderAt_short(t *testing.T) {
+	data := make([]byte, 2500)
+	rand.Read(data)
+
+	for _, size := range []int64{2600, 4096} {
+		_, err := TreeHashReaderAt(shortReaderAt{data}, size, &TreeOptions{ChunkSize: 1024, Workers: 1})
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("size %d: expect ErrUnexpectedEOF, got %v", size, err)
+		}
+	}
+
+	if _, err := TreeHashReaderAt(shortReaderAt{data}, 2500, &TreeOptions{ChunkSize: 1024}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestTreeHash_leafIsNotNode(t *testing.T) {
+	data := make([]byte, 2048)
+	rand.Read(data)
+
+	tree, _ := TreeHash(data, &TreeOptions{ChunkSize: 1024})
+
+	// The concatenation of the two leaf hashes must not verify as a leaf
+	forged := append(append([]byte{}, tree.levels[0][0]...), tree.levels[0][1]...)
+	if VerifyChunk(tree.Root(), forged, &Proof{Index: 0, Size: int64(len(forged)), ChunkSize: len(forged)}) {
+		t.Fatalf("internal node accepted as leaf")
+	}
+
+	if _, err := tree.Proof(2); err != ErrChunkOutOfRange {
+		t.Fatalf("expect ErrChunkOutOfRange, got %v", err)
+	}
+}
+
+func BenchmarkHash(b *testing.B) {
+	data := make([]byte, 64<<20)
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		Hash(data, 256)
+	}
+}
+
+func BenchmarkTreeHash(b *testing.B) {
+	data := make([]byte, 64<<20)
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		TreeHash(data, nil)
+	}
+}
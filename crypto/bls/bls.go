@@ -1,7 +1,7 @@
 package bls
 
 import (
-	"errors"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/herumi/bls-go-binary/bls"
@@ -13,52 +13,75 @@ type BLSKey struct {
 }
 
 // 风险函数，将 私钥分片 聚合为私钥，输入为 16 进制的私钥明文
-func (c *BLSKey) AggSK(sk []string) {
+func (c *BLSKey) AggSK(sk []string) error {
+	if err := initMinSignature(); err != nil {
+		return err
+	}
+
 	var aggSK bls.SecretKey
 
-	for _, k := range sk {
+	for i, k := range sk {
 		temp := bls.SecretKey{}
-		temp.DeserializeHexStr(k)
+		if err := temp.DeserializeHexStr(k); err != nil {
+			return fmt.Errorf("secret key %d: %w", i, err)
+		}
 		aggSK.Add(&temp)
 	}
 
 	c.priv = &aggSK
+	c.pub = aggSK.GetPublicKey()
+	return nil
 }
 
-func (c *BLSKey) AggPK(pk []string) {
+// AggPK 聚合公钥。不校验持有证明，参与方可以构造恶意公钥（rogue-key 攻击），
+// 公钥来源不可信时请使用 AggPKWithPoP
+func (c *BLSKey) AggPK(pk []string) error {
+	if err := initMinSignature(); err != nil {
+		return err
+	}
+
 	var aggPK bls.PublicKey
 
-	for _, k := range pk {
+	for i, k := range pk {
 		temp := bls.PublicKey{}
-		temp.DeserializeHexStr(k)
+		if err := temp.DeserializeHexStr(k); err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
 		aggPK.Add(&temp)
 	}
 
 	c.pub = &aggPK
+	return nil
 }
 
+// Sign 使用当前 ciphersuite 对消息签名，返回 16 进制编码的序列化签名
 func (c *BLSKey) Sign(msg string) (string, error) {
-	if c.priv == nil {
-		return "", errors.New("private key has not init")
+	sig, err := c.SignBytes([]byte(msg))
+	if err != nil {
+		return "", err
 	}
-	return c.priv.Sign(msg).GetHexString(), nil
+	return hex.EncodeToString(sig), nil
 }
 
+// CheckAggSign 校验所有公钥对同一条消息的签名之和。
+// 需要区分失败原因时请使用 FastAggregateVerify
 func CheckAggSign(pk []string, msg string, sig []string) bool {
 	// 聚合公钥
 	blsKey := BLSKey{}
-	blsKey.AggPK(pk)
+	if err := blsKey.AggPK(pk); err != nil {
+		return false
+	}
 
 	// 聚合签名
 	var aggSig bls.Sign
 	for _, s := range sig {
 		var tempSig bls.Sign
 
-		tempSig.DeserializeHexStr(s)
+		if err := tempSig.DeserializeHexStr(s); err != nil {
+			return false
+		}
 		aggSig.Add(&tempSig)
 	}
 
-	fmt.Println("聚合公钥：", blsKey.pub.SerializeToHexStr())
-	fmt.Println("聚合签名：", aggSig.SerializeToHexStr())
 	return aggSig.Verify(blsKey.pub, msg)
 }
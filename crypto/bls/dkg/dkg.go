@@ -0,0 +1,496 @@
+// Package dkg 实现基于 Feldman 承诺的 Pedersen 分布式密钥生成，
+// 委员会成员不借助可信分发者共同生成 crypto/bls 的 t-of-n 门限密钥。
+//
+// 协议分三轮，每轮每个参与方都会发出消息：
+//
+//  1. 分发：广播自己随机多项式的承诺，并私下发送给每个参与方一个分片
+//  2. 投诉：用承诺校验收到的分片，广播分片无效或缺失的分发者
+//  3. 申辩：被投诉的分发者广播被投诉的分片，申辩无效或缺席的分发者被取消资格
+//
+// 最终私钥分片为所有合格分发者所给分片之和，组验证向量为合格承诺之和，
+// 完整私钥在任何时刻都不存在
+package dkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/herumi/bls-go-binary/bls"
+
+	tbls "tools/crypto/bls"
+)
+
+const (
+	roundDeal = iota + 1
+	roundComplaint
+	roundJustification
+)
+
+var (
+	ErrInvalidRound       = errors.New("dkg: message out of round")
+	ErrNotEnoughQual      = errors.New("dkg: not enough qualified dealers")
+	ErrRoundIncomplete    = errors.New("dkg: round is not complete")
+	ErrDuplicateMessage   = errors.New("dkg: duplicate message")
+	ErrUnknownParticipant = errors.New("dkg: unknown participant")
+)
+
+// Participant 是单个参与方的 DKG 状态机，不是并发安全的
+type Participant struct {
+	ID        uint64
+	IDs       []uint64
+	Threshold int
+
+	// RoundTimeout 大于 0 时，Run 在每轮等待超时后带着已收到的消息进入下一轮，
+	// 未按时发出消息的分发者会被投诉或取消资格
+	RoundTimeout time.Duration
+
+	blsID bls.ID
+	msk   []bls.SecretKey
+	round int
+
+	commitments    map[uint64][]bls.PublicKey
+	shares         map[uint64]*bls.SecretKey
+	complaints     map[uint64][]uint64
+	justifications map[uint64]map[uint64]string
+}
+
+// Result 是 DKG 的输出
+type Result struct {
+	Group *tbls.ThresholdGroup
+	Share *tbls.KeyShare
+
+	Qualified    []uint64
+	Disqualified []uint64
+}
+
+// NewParticipant 创建参与方 id 的状态机，ids 为全部参与方（包括自己）
+func NewParticipant(id uint64, ids []uint64, threshold int) (*Participant, error) {
+	if err := tbls.Init(); err != nil {
+		return nil, err
+	}
+	if threshold < 1 || threshold > len(ids) {
+		return nil, tbls.ErrInvalidThreshold
+	}
+
+	seen := map[uint64]bool{}
+	for _, v := range ids {
+		if v == 0 || seen[v] {
+			return nil, tbls.ErrInvalidID
+		}
+		seen[v] = true
+	}
+	if !seen[id] {
+		return nil, ErrUnknownParticipant
+	}
+
+	blsID, err := tbls.ParticipantID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+
+	return &Participant{
+		ID:             id,
+		IDs:            ids,
+		Threshold:      threshold,
+		blsID:          blsID,
+		commitments:    make(map[uint64][]bls.PublicKey),
+		shares:         make(map[uint64]*bls.SecretKey),
+		complaints:     make(map[uint64][]uint64),
+		justifications: make(map[uint64]map[uint64]string),
+	}, nil
+}
+
+// Run 通过 tr 完成全部三轮并返回结果。
+// 每轮先发出本轮消息，再接收直到本轮消息齐全、RoundTimeout 超时或 ctx 结束
+func Run(ctx context.Context, p *Participant, tr Transport) (*Result, error) {
+	steps := []func() ([]*Message, error){p.Deal, p.Complaints, p.Justifications}
+
+	for _, step := range steps {
+		msgs, err := step()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			if err := tr.Send(ctx, m); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.collect(ctx, tr); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.Finish()
+}
+
+func (p *Participant) collect(ctx context.Context, tr Transport) error {
+	rctx := ctx
+	if p.RoundTimeout > 0 {
+		var cancel context.CancelFunc
+		rctx, cancel = context.WithTimeout(ctx, p.RoundTimeout)
+		defer cancel()
+	}
+
+	for !p.roundComplete() {
+		msg, err := tr.Receive(rctx)
+		if err != nil {
+			if ctx.Err() == nil && rctx.Err() != nil {
+				// 本轮超时，缺失的消息按未发送处理
+				return nil
+			}
+			return err
+		}
+
+		// 单条消息无效不影响协议继续，发送方会在后续轮次被投诉或取消资格
+		_ = p.Handle(msg)
+	}
+
+	return nil
+}
+
+// Deal 生成随机多项式，返回第一轮的承诺广播和发给各参与方的分片
+func (p *Participant) Deal() ([]*Message, error) {
+	if p.round != 0 {
+		return nil, ErrInvalidRound
+	}
+	p.round = roundDeal
+
+	var sk bls.SecretKey
+	sk.SetByCSPRNG()
+	p.msk = sk.GetMasterSecretKey(p.Threshold)
+	commitments := bls.GetMasterPublicKey(p.msk)
+	p.commitments[p.ID] = commitments
+
+	payload := commitmentsPayload{Commitments: make([]string, len(commitments))}
+	for i := range commitments {
+		payload.Commitments[i] = commitments[i].SerializeToHexStr()
+	}
+
+	msg, err := newMessage(roundDeal, MsgCommitments, p.ID, 0, payload)
+	if err != nil {
+		return nil, err
+	}
+	msgs := []*Message{msg}
+
+	for _, id := range p.IDs {
+		share, err := p.shareFor(id)
+		if err != nil {
+			return nil, err
+		}
+		if id == p.ID {
+			p.shares[id] = share
+			continue
+		}
+
+		msg, err := newMessage(roundDeal, MsgShare, p.ID, id, sharePayload{Share: share.SerializeToHexStr()})
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+// Complaints 校验收到的分片，返回第二轮的投诉广播（可能为空列表）
+func (p *Participant) Complaints() ([]*Message, error) {
+	if p.round != roundDeal {
+		return nil, ErrInvalidRound
+	}
+	p.round = roundComplaint
+
+	var accused []uint64
+	for _, dealer := range p.IDs {
+		if dealer == p.ID {
+			continue
+		}
+		if !p.validShare(dealer, p.ID, p.shares[dealer]) {
+			accused = append(accused, dealer)
+		}
+	}
+	p.complaints[p.ID] = accused
+
+	msg, err := newMessage(roundComplaint, MsgComplaints, p.ID, 0, complaintsPayload{Accused: accused})
+	if err != nil {
+		return nil, err
+	}
+	return []*Message{msg}, nil
+}
+
+// Justifications 公开被投诉的分片，返回第三轮的申辩广播（可能为空）
+func (p *Participant) Justifications() ([]*Message, error) {
+	if p.round != roundComplaint {
+		return nil, ErrInvalidRound
+	}
+	p.round = roundJustification
+
+	payload := justificationPayload{Shares: map[uint64]string{}}
+	for complainer, accused := range p.complaints {
+		if !slices.Contains(accused, p.ID) {
+			continue
+		}
+
+		share, err := p.shareFor(complainer)
+		if err != nil {
+			return nil, err
+		}
+		payload.Shares[complainer] = share.SerializeToHexStr()
+	}
+	p.justifications[p.ID] = payload.Shares
+
+	msg, err := newMessage(roundJustification, MsgJustification, p.ID, 0, payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*Message{msg}, nil
+}
+
+// Handle 处理收到的消息。允许提前收到下一轮的消息，
+// 重复、格式错误或来自未知参与方的消息返回错误并被忽略
+func (p *Participant) Handle(msg *Message) error {
+	if !slices.Contains(p.IDs, msg.From) || msg.From == p.ID {
+		return ErrUnknownParticipant
+	}
+	if msg.To != 0 && msg.To != p.ID {
+		return ErrUnknownParticipant
+	}
+	if msg.Round < p.round {
+		// 上一轮已经结算，迟到的消息不再接受
+		return ErrInvalidRound
+	}
+
+	switch {
+	case msg.Type == MsgCommitments && msg.Round == roundDeal && msg.To == 0:
+		if _, ok := p.commitments[msg.From]; ok {
+			return ErrDuplicateMessage
+		}
+
+		var payload commitmentsPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return err
+		}
+		if len(payload.Commitments) != p.Threshold {
+			p.commitments[msg.From] = nil
+			return fmt.Errorf("dkg: dealer %d committed to %d coefficients", msg.From, len(payload.Commitments))
+		}
+
+		commitments := make([]bls.PublicKey, len(payload.Commitments))
+		for i, c := range payload.Commitments {
+			if err := commitments[i].DeserializeHexStr(c); err != nil {
+				p.commitments[msg.From] = nil
+				return err
+			}
+		}
+		p.commitments[msg.From] = commitments
+
+	case msg.Type == MsgShare && msg.Round == roundDeal && msg.To == p.ID:
+		if _, ok := p.shares[msg.From]; ok {
+			return ErrDuplicateMessage
+		}
+
+		var payload sharePayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return err
+		}
+
+		var share bls.SecretKey
+		if err := share.DeserializeHexStr(payload.Share); err != nil {
+			p.shares[msg.From] = nil
+			return err
+		}
+		p.shares[msg.From] = &share
+
+	case msg.Type == MsgComplaints && msg.Round == roundComplaint && msg.To == 0:
+		if _, ok := p.complaints[msg.From]; ok {
+			return ErrDuplicateMessage
+		}
+
+		var payload complaintsPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return err
+		}
+		p.complaints[msg.From] = payload.Accused
+
+	case msg.Type == MsgJustification && msg.Round == roundJustification && msg.To == 0:
+		if _, ok := p.justifications[msg.From]; ok {
+			return ErrDuplicateMessage
+		}
+
+		var payload justificationPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return err
+		}
+		if payload.Shares == nil {
+			payload.Shares = map[uint64]string{}
+		}
+		p.justifications[msg.From] = payload.Shares
+
+	default:
+		return fmt.Errorf("%w: %s in round %d", ErrInvalidRound, msg.Type, msg.Round)
+	}
+
+	return nil
+}
+
+// Finish 根据投诉和申辩确定合格分发者集合，计算自己的私钥分片和组验证向量。
+// 所有诚实参与方收到一致的广播时会得到相同的合格集合和组公钥
+func (p *Participant) Finish() (*Result, error) {
+	if p.round != roundJustification {
+		return nil, ErrRoundIncomplete
+	}
+
+	res := &Result{}
+	var (
+		secret       bls.SecretKey
+		verification = make([]bls.PublicKey, p.Threshold)
+	)
+
+	for _, dealer := range p.IDs {
+		share, ok := p.qualify(dealer)
+		if !ok {
+			res.Disqualified = append(res.Disqualified, dealer)
+			continue
+		}
+		res.Qualified = append(res.Qualified, dealer)
+
+		secret.Add(share)
+		for i := range verification {
+			verification[i].Add(&p.commitments[dealer][i])
+		}
+	}
+
+	if len(res.Qualified) < p.Threshold {
+		return nil, fmt.Errorf("%w: %d qualified, threshold %d", ErrNotEnoughQual, len(res.Qualified), p.Threshold)
+	}
+
+	vector := make([]string, len(verification))
+	for i := range verification {
+		vector[i] = verification[i].SerializeToHexStr()
+	}
+
+	group, err := tbls.NewThresholdGroup(vector)
+	if err != nil {
+		return nil, err
+	}
+	share, err := tbls.NewKeyShare(p.ID, secret.SerializeToHexStr(), group)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Group, res.Share = group, share
+	return res, nil
+}
+
+// qualify 判断分发者是否合格，合格时返回它给自己的有效分片。
+// 分发者在以下情况被取消资格：承诺缺失或格式错误；
+// 任何一条针对它的投诉没有得到有效申辩
+func (p *Participant) qualify(dealer uint64) (*bls.SecretKey, bool) {
+	if len(p.commitments[dealer]) != p.Threshold {
+		return nil, false
+	}
+
+	mine := p.shares[dealer]
+	for _, complainer := range p.IDs {
+		if !slices.Contains(p.complaints[complainer], dealer) {
+			continue
+		}
+
+		revealed, ok := p.justifications[dealer][complainer]
+		if !ok {
+			return nil, false
+		}
+
+		var share bls.SecretKey
+		if err := share.DeserializeHexStr(revealed); err != nil || !p.validShare(dealer, complainer, &share) {
+			return nil, false
+		}
+		if complainer == p.ID {
+			mine = &share
+		}
+	}
+
+	if !p.validShare(dealer, p.ID, mine) {
+		return nil, false
+	}
+
+	return mine, true
+}
+
+// validShare 用分发者的承诺校验发给 id 的分片
+func (p *Participant) validShare(dealer, id uint64, share *bls.SecretKey) bool {
+	commitments := p.commitments[dealer]
+	if share == nil || len(commitments) != p.Threshold {
+		return false
+	}
+
+	blsID, err := tbls.ParticipantID(id)
+	if err != nil {
+		return false
+	}
+
+	var expected bls.PublicKey
+	if err := expected.Set(commitments, &blsID); err != nil {
+		return false
+	}
+
+	return share.GetPublicKey().IsEqual(&expected)
+}
+
+func (p *Participant) shareFor(id uint64) (*bls.SecretKey, error) {
+	blsID, err := tbls.ParticipantID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var share bls.SecretKey
+	if err := share.Set(p.msk, &blsID); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// roundComplete 判断当前轮次是否已收到其他所有参与方的消息
+func (p *Participant) roundComplete() bool {
+	for _, id := range p.IDs {
+		if id == p.ID {
+			continue
+		}
+
+		switch p.round {
+		case roundDeal:
+			if _, ok := p.commitments[id]; !ok {
+				return false
+			}
+			if _, ok := p.shares[id]; !ok {
+				return false
+			}
+		case roundComplaint:
+			if _, ok := p.complaints[id]; !ok {
+				return false
+			}
+		case roundJustification:
+			if _, ok := p.justifications[id]; !ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func newMessage(round int, typ MessageType, from, to uint64, payload any) (*Message, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{Round: round, Type: typ, From: from, To: to, Payload: raw}, nil
+}
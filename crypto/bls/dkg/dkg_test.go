@@ -0,0 +1,269 @@
+package dkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/herumi/bls-go-binary/bls"
+
+	tbls "tools/crypto/bls"
+)
+
+// runDKG 让 ids 中的每个参与方并发执行 Run，wrap 可以替换某个参与方的通道
+func runDKG(t *testing.T, ids []uint64, threshold int, wrap func(id uint64, tr Transport) Transport) map[uint64]*Result {
+	t.Helper()
+
+	bus := NewMemoryBus(ids)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	type out struct {
+		id  uint64
+		res *Result
+		err error
+	}
+	ch := make(chan out, len(ids))
+
+	for _, id := range ids {
+		p, err := NewParticipant(id, ids, threshold)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		tr := bus.Transport(id)
+		if wrap != nil {
+			tr = wrap(id, tr)
+		}
+
+		go func() {
+			res, err := Run(ctx, p, tr)
+			ch <- out{id, res, err}
+		}()
+	}
+
+	results := map[uint64]*Result{}
+	for range ids {
+		o := <-ch
+		if o.err != nil {
+			t.Fatalf("participant %d: %v", o.id, o.err)
+		}
+		results[o.id] = o.res
+	}
+
+	return results
+}
+
+// checkAgreement 校验诚实参与方得到相同的组公钥，且任意 threshold 个分片可以恢复组签名
+func checkAgreement(t *testing.T, results map[uint64]*Result, honest []uint64) {
+	t.Helper()
+
+	first := results[honest[0]]
+	for _, id := range honest {
+		r := results[id]
+		if r.Group.PublicKeyHex() != first.Group.PublicKeyHex() {
+			t.Fatalf("participant %d: group public key differs", id)
+		}
+		if !slices.Equal(r.Qualified, first.Qualified) {
+			t.Fatalf("participant %d: bad qualified set: %v %v", id, r.Qualified, first.Qualified)
+		}
+	}
+
+	message := "Hello, DKG!"
+	var partials []*tbls.PartialSignature
+	for _, id := range honest[:first.Group.Threshold] {
+		p, err := results[id].Share.SignPartial(message)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		partials = append(partials, p)
+	}
+
+	sig, err := first.Group.Recover(message, partials)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !first.Group.Verify(message, sig) {
+		t.Fatalf("group signature invalid")
+	}
+}
+
+func TestDKG(t *testing.T) {
+	ids := []uint64{1, 2, 3, 4, 5}
+	results := runDKG(t, ids, 3, nil)
+
+	checkAgreement(t, results, ids)
+	if !slices.Equal(results[1].Qualified, ids) || len(results[1].Disqualified) != 0 {
+		t.Fatalf("bad: %v %v", results[1].Qualified, results[1].Disqualified)
+	}
+
+	// The joint key is usable with any 3 of the 5 shares
+	message := "any quorum"
+	var partials []*tbls.PartialSignature
+	for _, id := range []uint64{5, 3, 1} {
+		p, _ := results[id].Share.SignPartial(message)
+		partials = append(partials, p)
+	}
+	sig, err := results[2].Group.Recover(message, partials)
+	if err != nil || !results[4].Group.Verify(message, sig) {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+// tamper 在发送前修改消息
+type tamper struct {
+	Transport
+	fn func(msg *Message) *Message
+}
+
+func (t *tamper) Send(ctx context.Context, msg *Message) error {
+	if msg = t.fn(msg); msg == nil {
+		return nil
+	}
+	return t.Transport.Send(ctx, msg)
+}
+
+func randomShare() string {
+	var sk bls.SecretKey
+	sk.SetByCSPRNG()
+	return sk.SerializeToHexStr()
+}
+
+func TestDKG_complaintJustified(t *testing.T) {
+	ids := []uint64{1, 2, 3, 4, 5}
+
+	// Dealer 1 sends participant 2 a bad share, but reveals the correct one when accused
+	results := runDKG(t, ids, 3, func(id uint64, tr Transport) Transport {
+		if id != 1 {
+			return tr
+		}
+		return &tamper{Transport: tr, fn: func(msg *Message) *Message {
+			if msg.Type == MsgShare && msg.To == 2 {
+				msg.Payload, _ = json.Marshal(sharePayload{Share: randomShare()})
+			}
+			return msg
+		}}
+	})
+
+	checkAgreement(t, results, ids)
+	if len(results[3].Disqualified) != 0 {
+		t.Fatalf("bad: %v", results[3].Disqualified)
+	}
+}
+
+func TestDKG_disqualify(t *testing.T) {
+	ids := []uint64{1, 2, 3, 4, 5}
+
+	// Dealer 1 sends a bad share and a bad justification, dealer 4 never justifies
+	results := runDKG(t, ids, 3, func(id uint64, tr Transport) Transport {
+		switch id {
+		case 1:
+			return &tamper{Transport: tr, fn: func(msg *Message) *Message {
+				switch {
+				case msg.Type == MsgShare && msg.To == 2:
+					msg.Payload, _ = json.Marshal(sharePayload{Share: randomShare()})
+				case msg.Type == MsgJustification:
+					msg.Payload, _ = json.Marshal(justificationPayload{Shares: map[uint64]string{2: randomShare()}})
+				}
+				return msg
+			}}
+		case 4:
+			return &tamper{Transport: tr, fn: func(msg *Message) *Message {
+				switch {
+				case msg.Type == MsgShare && msg.To == 3:
+					msg.Payload, _ = json.Marshal(sharePayload{Share: randomShare()})
+				case msg.Type == MsgJustification:
+					msg.Payload, _ = json.Marshal(justificationPayload{})
+				}
+				return msg
+			}}
+		}
+		return tr
+	})
+
+	// The cheating dealers do not see their own tampering, only honest parties are checked
+	honest := []uint64{2, 3, 5}
+	checkAgreement(t, results, honest)
+	for _, id := range honest {
+		if !slices.Equal(results[id].Disqualified, []uint64{1, 4}) {
+			t.Fatalf("participant %d: bad disqualified set: %v", id, results[id].Disqualified)
+		}
+	}
+}
+
+func TestDKG_missingDealer(t *testing.T) {
+	ids := []uint64{1, 2, 3, 4}
+	bus := NewMemoryBus(ids)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Participant 4 is offline; the others move on after the round timeout
+	type out struct {
+		res *Result
+		err error
+	}
+	ch := make(chan out, 3)
+	for _, id := range ids[:3] {
+		p, _ := NewParticipant(id, ids, 2)
+		p.RoundTimeout = 200 * time.Millisecond
+
+		go func() {
+			res, err := Run(ctx, p, bus.Transport(p.ID))
+			ch <- out{res, err}
+		}()
+	}
+
+	var first *Result
+	for range 3 {
+		o := <-ch
+		if o.err != nil {
+			t.Fatalf("err: %v", o.err)
+		}
+		if !slices.Equal(o.res.Disqualified, []uint64{4}) {
+			t.Fatalf("bad: %v", o.res.Disqualified)
+		}
+		if first != nil && first.Group.PublicKeyHex() != o.res.Group.PublicKeyHex() {
+			t.Fatalf("group public key differs")
+		}
+		first = o.res
+	}
+}
+
+func TestParticipant_invalid(t *testing.T) {
+	if _, err := NewParticipant(1, []uint64{1, 2, 3}, 4); !errors.Is(err, tbls.ErrInvalidThreshold) {
+		t.Fatalf("expect ErrInvalidThreshold, got %v", err)
+	}
+	if _, err := NewParticipant(1, []uint64{1, 2, 2}, 2); !errors.Is(err, tbls.ErrInvalidID) {
+		t.Fatalf("expect ErrInvalidID, got %v", err)
+	}
+	if _, err := NewParticipant(9, []uint64{1, 2, 3}, 2); !errors.Is(err, ErrUnknownParticipant) {
+		t.Fatalf("expect ErrUnknownParticipant, got %v", err)
+	}
+
+	p, _ := NewParticipant(1, []uint64{1, 2, 3}, 2)
+	if _, err := p.Complaints(); !errors.Is(err, ErrInvalidRound) {
+		t.Fatalf("expect ErrInvalidRound, got %v", err)
+	}
+	if _, err := p.Finish(); !errors.Is(err, ErrRoundIncomplete) {
+		t.Fatalf("expect ErrRoundIncomplete, got %v", err)
+	}
+
+	if _, err := p.Deal(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	msg, _ := newMessage(roundDeal, MsgShare, 2, 1, sharePayload{Share: randomShare()})
+	if err := p.Handle(msg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := p.Handle(msg); !errors.Is(err, ErrDuplicateMessage) {
+		t.Fatalf("expect ErrDuplicateMessage, got %v", err)
+	}
+
+	msg.From = 7
+	if err := p.Handle(msg); !errors.Is(err, ErrUnknownParticipant) {
+		t.Fatalf("expect ErrUnknownParticipant, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package dkg
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+type MessageType string
+
+const (
+	MsgCommitments   MessageType = "commitments"
+	MsgShare         MessageType = "share"
+	MsgComplaints    MessageType = "complaints"
+	MsgJustification MessageType = "justification"
+)
+
+// Message 是 DKG 各轮之间传递的消息，可以直接 JSON 序列化后经 RabbitMQ、
+// websocket 等通道传输。To 为 0 表示广播。
+//
+// MsgShare 携带的是私钥分片，传输层必须保证定向消息的机密性和来源认证；
+// 广播消息必须保证所有参与方收到的内容一致
+type Message struct {
+	Round   int             `json:"round"`
+	Type    MessageType     `json:"type"`
+	From    uint64          `json:"from"`
+	To      uint64          `json:"to"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type commitmentsPayload struct {
+	Commitments []string `json:"commitments"`
+}
+
+type sharePayload struct {
+	Share string `json:"share"`
+}
+
+type complaintsPayload struct {
+	Accused []uint64 `json:"accused"`
+}
+
+type justificationPayload struct {
+	// 投诉方 id -> 公开的私钥分片
+	Shares map[uint64]string `json:"shares"`
+}
+
+func (m *Message) Marshal() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+func UnmarshalMessage(data []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Transport 抽象了 DKG 的消息通道
+type Transport interface {
+	// Send 发送消息，msg.To 为 0 时广播给除自己以外的所有参与方
+	Send(ctx context.Context, msg *Message) error
+	// Receive 阻塞直到收到一条发给自己的消息
+	Receive(ctx context.Context) (*Message, error)
+}
+
+// MemoryBus 是进程内的消息总线，用于测试和单机模拟。
+// 消息在投递前经过一次 JSON 编解码，与真实传输保持一致
+type MemoryBus struct {
+	mu     sync.Mutex
+	queues map[uint64]chan []byte
+}
+
+func NewMemoryBus(ids []uint64) *MemoryBus {
+	b := &MemoryBus{queues: make(map[uint64]chan []byte)}
+	for _, id := range ids {
+		b.queues[id] = make(chan []byte, 16*len(ids))
+	}
+	return b
+}
+
+// Transport 返回参与方 id 的通道
+func (b *MemoryBus) Transport(id uint64) Transport {
+	return &memoryTransport{bus: b, id: id}
+}
+
+type memoryTransport struct {
+	bus *MemoryBus
+	id  uint64
+}
+
+func (t *memoryTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Marshal()
+	if err != nil {
+		return err
+	}
+
+	t.bus.mu.Lock()
+	var targets []chan []byte
+	if msg.To == 0 {
+		for id, q := range t.bus.queues {
+			if id != t.id {
+				targets = append(targets, q)
+			}
+		}
+	} else if q, ok := t.bus.queues[msg.To]; ok {
+		targets = append(targets, q)
+	}
+	t.bus.mu.Unlock()
+
+	if len(targets) == 0 {
+		return ErrUnknownParticipant
+	}
+
+	for _, q := range targets {
+		select {
+		case q <- data:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (t *memoryTransport) Receive(ctx context.Context) (*Message, error) {
+	select {
+	case data := <-t.bus.queues[t.id]:
+		return UnmarshalMessage(data)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
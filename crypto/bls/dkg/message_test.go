@@ -0,0 +1,58 @@
+package dkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMessage_Marshal(t *testing.T) {
+	msg, err := newMessage(roundComplaint, MsgComplaints, 3, 0, complaintsPayload{Accused: []uint64{1, 5}})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	data, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	got, err := UnmarshalMessage(data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got.Round != msg.Round || got.Type != msg.Type || got.From != 3 || got.To != 0 || string(got.Payload) != string(msg.Payload) {
+		t.Fatalf("bad: %+v", got)
+	}
+}
+
+func TestMemoryBus(t *testing.T) {
+	bus := NewMemoryBus([]uint64{1, 2, 3})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	msg := &Message{Round: 1, Type: MsgCommitments, From: 1, Payload: []byte("{}")}
+	if err := bus.Transport(1).Send(ctx, msg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for _, id := range []uint64{2, 3} {
+		got, err := bus.Transport(id).Receive(ctx)
+		if err != nil || got.From != 1 {
+			t.Fatalf("participant %d: bad: %+v %v", id, got, err)
+		}
+	}
+
+	// Broadcasts are not echoed back to the sender
+	short, cancel2 := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel2()
+	if _, err := bus.Transport(1).Receive(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect DeadlineExceeded, got %v", err)
+	}
+
+	msg.To = 9
+	if err := bus.Transport(1).Send(ctx, msg); !errors.Is(err, ErrUnknownParticipant) {
+		t.Fatalf("expect ErrUnknownParticipant, got %v", err)
+	}
+}
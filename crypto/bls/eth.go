@@ -0,0 +1,256 @@
+package bls
+
+import (
+	"fmt"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+// EthKey 是 SuiteEthereum 下的密钥：公钥在 G1（48 字节），签名在 G2（96 字节），
+// 与以太坊共识层的签名、持有证明和聚合签名兼容。
+// 底层库编译时固定公钥在 G2，因此该布局直接使用曲线运算实现，
+// 所有函数都要求先调用 Configure(SuiteEthereum)
+type EthKey struct {
+	priv *bls.Fr
+	pub  *bls.G1
+}
+
+// GenerateEthKey 使用 CSPRNG 生成新的密钥对
+func GenerateEthKey() (*EthKey, error) {
+	if err := initEthereum(); err != nil {
+		return nil, err
+	}
+
+	var sk bls.Fr
+	for sk.IsZero() {
+		sk.SetByCSPRNG()
+	}
+
+	return newEthKey(&sk), nil
+}
+
+// EthKeyFromSecretKey 从 32 字节大端序私钥恢复密钥对
+func EthKeyFromSecretKey(b []byte) (*EthKey, error) {
+	if err := initEthereum(); err != nil {
+		return nil, err
+	}
+
+	var sk bls.Fr
+	if err := sk.Deserialize(b); err != nil {
+		return nil, fmt.Errorf("deserialize secret key: %w", err)
+	}
+	if sk.IsZero() {
+		return nil, ErrZeroKey
+	}
+
+	return newEthKey(&sk), nil
+}
+
+func newEthKey(sk *bls.Fr) *EthKey {
+	var pub bls.G1
+	bls.G1Mul(&pub, &ietfG1Gen, sk)
+
+	return &EthKey{priv: sk, pub: &pub}
+}
+
+func (k *EthKey) SecretKey() []byte {
+	return k.priv.Serialize()
+}
+
+func (k *EthKey) PublicKey() []byte {
+	return k.pub.Serialize()
+}
+
+// Sign 对消息签名，返回 96 字节的压缩签名
+func (k *EthKey) Sign(msg []byte) ([]byte, error) {
+	if err := initEthereum(); err != nil {
+		return nil, err
+	}
+
+	var h bls.G2
+	if err := h.HashAndMapTo(msg); err != nil {
+		return nil, err
+	}
+
+	var sig bls.G2
+	bls.G2Mul(&sig, &h, k.priv)
+	return sig.Serialize(), nil
+}
+
+// ProofOfPossession 生成公钥的持有证明
+func (k *EthKey) ProofOfPossession() ([]byte, error) {
+	if err := initEthereum(); err != nil {
+		return nil, err
+	}
+
+	h, err := hashToG2(k.pub.Serialize(), SuiteEthereum.PopDST)
+	if err != nil {
+		return nil, err
+	}
+
+	var pop bls.G2
+	bls.G2Mul(&pop, h, k.priv)
+	return pop.Serialize(), nil
+}
+
+// EthVerify 校验单个签名
+func EthVerify(pk, msg, sig []byte) error {
+	return EthFastAggregateVerify([][]byte{pk}, msg, sig)
+}
+
+// EthVerifyPoP 校验公钥的持有证明
+func EthVerifyPoP(pk, pop []byte) error {
+	if err := initEthereum(); err != nil {
+		return err
+	}
+
+	pub, err := parseEthPublicKey(pk)
+	if err != nil {
+		return err
+	}
+	s, err := parseEthSignature(pop)
+	if err != nil {
+		return err
+	}
+
+	h, err := hashToG2(pub.Serialize(), SuiteEthereum.PopDST)
+	if err != nil {
+		return err
+	}
+
+	if !ethPairingCheck(s, []bls.G1{*pub}, []bls.G2{*h}) {
+		return ErrInvalidPoP
+	}
+	return nil
+}
+
+// EthAggregateSignatures 将签名相加为聚合签名
+func EthAggregateSignatures(sigs [][]byte) ([]byte, error) {
+	if err := initEthereum(); err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	var agg bls.G2
+	for i := range sigs {
+		s, err := parseEthSignature(sigs[i])
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+		bls.G2Add(&agg, &agg, s)
+	}
+
+	return agg.Serialize(), nil
+}
+
+// EthFastAggregateVerify 校验所有公钥对同一条消息的聚合签名，公钥必须已通过 EthVerifyPoP
+func EthFastAggregateVerify(pks [][]byte, msg, sig []byte) error {
+	if err := initEthereum(); err != nil {
+		return err
+	}
+	if len(pks) == 0 {
+		return ErrEmptyInput
+	}
+
+	var agg bls.G1
+	for i := range pks {
+		pub, err := parseEthPublicKey(pks[i])
+		if err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
+		bls.G1Add(&agg, &agg, pub)
+	}
+
+	s, err := parseEthSignature(sig)
+	if err != nil {
+		return err
+	}
+
+	var h bls.G2
+	if err := h.HashAndMapTo(msg); err != nil {
+		return err
+	}
+
+	if !ethPairingCheck(s, []bls.G1{agg}, []bls.G2{h}) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// EthAggregateVerify 校验聚合签名，第 i 个签名者用 pks[i] 对 msgs[i] 签名
+func EthAggregateVerify(pks, msgs [][]byte, sig []byte) error {
+	if err := initEthereum(); err != nil {
+		return err
+	}
+	if len(pks) != len(msgs) {
+		return ErrLengthMismatch
+	}
+	if len(pks) == 0 {
+		return ErrEmptyInput
+	}
+
+	s, err := parseEthSignature(sig)
+	if err != nil {
+		return err
+	}
+
+	pubs := make([]bls.G1, len(pks))
+	hashes := make([]bls.G2, len(pks))
+	for i := range pks {
+		pub, err := parseEthPublicKey(pks[i])
+		if err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
+		pubs[i] = *pub
+
+		if err := hashes[i].HashAndMapTo(msgs[i]); err != nil {
+			return err
+		}
+	}
+
+	if !ethPairingCheck(s, pubs, hashes) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func initEthereum() error {
+	if err := Init(); err != nil {
+		return err
+	}
+	if CurrentSuite() != SuiteEthereum {
+		return ErrUnsupportedSuite
+	}
+	return nil
+}
+
+func parseEthPublicKey(b []byte) (*bls.G1, error) {
+	var pub bls.G1
+	if err := pub.Deserialize(b); err != nil {
+		return nil, fmt.Errorf("deserialize public key: %w", err)
+	}
+	if pub.IsZero() {
+		return nil, ErrZeroKey
+	}
+	return &pub, nil
+}
+
+func parseEthSignature(b []byte) (*bls.G2, error) {
+	var sig bls.G2
+	if err := sig.Deserialize(b); err != nil {
+		return nil, fmt.Errorf("deserialize signature: %w", err)
+	}
+	return &sig, nil
+}
+
+// ethPairingCheck 计算 e(-g1, sig) · Π e(pub_i, h_i) == 1
+func ethPairingCheck(sig *bls.G2, pubs []bls.G1, hashes []bls.G2) bool {
+	var neg bls.G1
+	bls.G1Neg(&neg, &ietfG1Gen)
+
+	g1 := append([]bls.G1{neg}, pubs...)
+	g2 := append([]bls.G2{*sig}, hashes...)
+	return pairingIsOne(g1, g2)
+}
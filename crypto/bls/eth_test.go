@@ -0,0 +1,94 @@
+package bls
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestEthKey_vector(t *testing.T) {
+	useSuite(t, SuiteEthereum)
+
+	// Ethereum consensus spec bls/sign test case sign_case_84d45c9c7cca6b92
+	sk, _ := hex.DecodeString("263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3")
+	msg, _ := hex.DecodeString("5656565656565656565656565656565656565656565656565656565656565656")
+
+	key, err := EthKeyFromSecretKey(sk)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if hex.EncodeToString(key.SecretKey()) != hex.EncodeToString(sk) {
+		t.Fatalf("bad: %x", key.SecretKey())
+	}
+	if exp := "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a"; hex.EncodeToString(key.PublicKey()) != exp {
+		t.Fatalf("bad: %x", key.PublicKey())
+	}
+
+	sig, err := key.Sign(msg)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	exp := "882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c2" +
+		"0767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb"
+	if hex.EncodeToString(sig) != exp {
+		t.Fatalf("bad: %x", sig)
+	}
+
+	if err := EthVerify(key.PublicKey(), msg, sig); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := EthVerify(key.PublicKey(), msg[1:], sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestEthKey_aggregate(t *testing.T) {
+	useSuite(t, SuiteEthereum)
+
+	var pks, msgs, sigs, same [][]byte
+	for i := 0; i < 3; i++ {
+		key, err := GenerateEthKey()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		pop, _ := key.ProofOfPossession()
+		if err := EthVerifyPoP(key.PublicKey(), pop); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		msg := []byte{byte(i)}
+		sig, _ := key.Sign(msg)
+		s, _ := key.Sign([]byte("same"))
+
+		pks, msgs, sigs, same = append(pks, key.PublicKey()), append(msgs, msg), append(sigs, sig), append(same, s)
+	}
+
+	agg, err := EthAggregateSignatures(sigs)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := EthAggregateVerify(pks, msgs, agg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := EthAggregateVerify(pks[:2], msgs[:2], agg); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+
+	aggSame, _ := EthAggregateSignatures(same)
+	if err := EthFastAggregateVerify(pks, []byte("same"), aggSame); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A signature is not a valid proof of possession
+	if err := EthVerifyPoP(pks[0], sigs[0]); !errors.Is(err, ErrInvalidPoP) {
+		t.Fatalf("expect ErrInvalidPoP, got %v", err)
+	}
+}
+
+func TestEthKey_wrongSuite(t *testing.T) {
+	if _, err := GenerateEthKey(); !errors.Is(err, ErrUnsupportedSuite) {
+		t.Fatalf("expect ErrUnsupportedSuite, got %v", err)
+	}
+}
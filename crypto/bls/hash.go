@@ -0,0 +1,106 @@
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+var errInvalidDST = errors.New("hash-to-curve dst must be 1 to 255 bytes")
+
+// expandMessageXMD 实现 RFC 9380 5.3.1 的 expand_message_xmd（SHA-256）
+func expandMessageXMD(msg []byte, dst string, n int) ([]byte, error) {
+	if len(dst) == 0 || len(dst) > 255 {
+		return nil, errInvalidDST
+	}
+
+	ell := (n + sha256.Size - 1) / sha256.Size
+	if ell > 255 || n > 65535 {
+		return nil, errors.New("expand_message_xmd: output too long")
+	}
+
+	dstPrime := append([]byte(dst), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, sha256.BlockSize))
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	out := append(make([]byte, 0, ell*sha256.Size), bi...)
+	for i := 2; i <= ell; i++ {
+		x := make([]byte, sha256.Size)
+		for j := range x {
+			x[j] = b0[j] ^ bi[j]
+		}
+
+		h.Reset()
+		h.Write(x)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+
+	return out[:n], nil
+}
+
+// hashToG1 实现 BLS12381G1_XMD:SHA-256_SSWU_RO_，DST 由调用方指定，
+// 不依赖底层库的全局 DST。需要 IRTF 的 map-to-curve 模式
+func hashToG1(msg []byte, dst string) (*bls.G1, error) {
+	u, err := expandMessageXMD(msg, dst, 2*64)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bls.G1
+	for i := 0; i < 2; i++ {
+		var e bls.Fp
+		if err := e.SetBigEndianMod(u[i*64 : (i+1)*64]); err != nil {
+			return nil, err
+		}
+
+		var p bls.G1
+		if err := bls.MapToG1(&p, &e); err != nil {
+			return nil, err
+		}
+		bls.G1Add(&out, &out, &p)
+	}
+
+	return &out, nil
+}
+
+// hashToG2 实现 BLS12381G2_XMD:SHA-256_SSWU_RO_
+func hashToG2(msg []byte, dst string) (*bls.G2, error) {
+	u, err := expandMessageXMD(msg, dst, 2*2*64)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bls.G2
+	for i := 0; i < 2; i++ {
+		var e bls.Fp2
+		for j := 0; j < 2; j++ {
+			off := (2*i + j) * 64
+			if err := e.D[j].SetBigEndianMod(u[off : off+64]); err != nil {
+				return nil, err
+			}
+		}
+
+		var p bls.G2
+		if err := bls.MapToG2(&p, &e); err != nil {
+			return nil, err
+		}
+		bls.G2Add(&out, &out, &p)
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,61 @@
+package bls
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+func TestHashToCurve(t *testing.T) {
+	useSuite(t, SuitePoP)
+
+	// RFC 9380 J.9.1, BLS12381G1_XMD:SHA-256_SSWU_RO_, msg "abc"
+	p, err := hashToG1([]byte("abc"), "QUUX-V01-CS02-with-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	exp := "1 3567bc5ef9c690c2ab2ecdf6a96ef1c139cc0b2f284dca0a9a7943388a49a3aee664ba5379a7655d3c68900be2f6903 " +
+		"b9c15f3fe6e5cf4211f346271d7b01c8f3b28be689c8429c85b67af215533311f0b8dfaaa154fa6b88176c229f2885d"
+	if p.GetString(16) != exp {
+		t.Fatalf("bad: %s", p.GetString(16))
+	}
+
+	// The configured signing DST produces the same point as the library's own hash
+	var lib bls.G1
+	lib.HashAndMapTo([]byte("message"))
+	own, _ := hashToG1([]byte("message"), SuitePoP.ID)
+	if !lib.IsEqual(own) {
+		t.Fatalf("hash to G1 differs from the library")
+	}
+
+	useSuite(t, SuiteEthereum)
+
+	var lib2 bls.G2
+	lib2.HashAndMapTo([]byte("message"))
+	own2, _ := hashToG2([]byte("message"), SuiteEthereum.ID)
+	if !lib2.IsEqual(own2) {
+		t.Fatalf("hash to G2 differs from the library")
+	}
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	// RFC 9380 K.1
+	dst := "QUUX-V01-CS02-with-expander-SHA256-128"
+	for msg, exp := range map[string]string{
+		"":    "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235",
+		"abc": "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615",
+	} {
+		out, err := expandMessageXMD([]byte(msg), dst, 32)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if hex.EncodeToString(out) != exp {
+			t.Fatalf("%q: bad: %x", msg, out)
+		}
+	}
+
+	if _, err := expandMessageXMD(nil, "", 32); err == nil {
+		t.Fatalf("expect error for empty dst")
+	}
+}
@@ -0,0 +1,276 @@
+package bls
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+var (
+	initOnce sync.Once
+	initErr  error
+)
+
+var (
+	ErrNoSecretKey    = errors.New("private key has not init")
+	ErrNoPublicKey    = errors.New("public key has not init")
+	ErrZeroKey        = errors.New("bls key is zero")
+	ErrInvalidPoP     = errors.New("invalid proof of possession")
+	ErrLengthMismatch = errors.New("input lengths mismatch")
+)
+
+// Init 初始化 BLS12-381 曲线，并开启公钥和签名的子群校验。
+// 可以重复并发调用，只有第一次生效，ciphersuite 由 Configure 设置
+func Init() error {
+	initOnce.Do(func() {
+		if initErr = bls.Init(bls.BLS12_381); initErr != nil {
+			return
+		}
+
+		bls.VerifyPublicKeyOrder(true)
+		bls.VerifySignatureOrder(true)
+		bls.VerifyOrderG1(true)
+		bls.VerifyOrderG2(true)
+
+		bls.GetGeneratorOfPublicKey(&legacyG2Gen)
+		initErr = ietfG1Gen.SetString(ietfG1Generator, 16)
+	})
+
+	return initErr
+}
+
+// GenerateBLSKey 使用 CSPRNG 生成新的密钥对
+func GenerateBLSKey() (*BLSKey, error) {
+	if err := initMinSignature(); err != nil {
+		return nil, err
+	}
+
+	var sk bls.SecretKey
+	sk.SetByCSPRNG()
+
+	return &BLSKey{
+		priv: &sk,
+		pub:  sk.GetPublicKey(),
+	}, nil
+}
+
+// BLSKeyFromSecretKey 从序列化的私钥恢复密钥对
+func BLSKeyFromSecretKey(b []byte) (*BLSKey, error) {
+	if err := initMinSignature(); err != nil {
+		return nil, err
+	}
+
+	var sk bls.SecretKey
+	if err := sk.Deserialize(b); err != nil {
+		return nil, fmt.Errorf("deserialize secret key: %w", err)
+	}
+	if sk.IsZero() {
+		return nil, ErrZeroKey
+	}
+
+	return &BLSKey{
+		priv: &sk,
+		pub:  sk.GetPublicKey(),
+	}, nil
+}
+
+// BLSKeyFromSecretKeyHex 从 16 进制私钥恢复密钥对
+func BLSKeyFromSecretKeyHex(s string) (*BLSKey, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return BLSKeyFromSecretKey(b)
+}
+
+// BLSKeyFromPublicKey 构造只能验签的公钥
+func BLSKeyFromPublicKey(b []byte) (*BLSKey, error) {
+	pub, err := parsePublicKey(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BLSKey{pub: pub}, nil
+}
+
+// BLSKeyFromPublicKeyHex 从 16 进制公钥构造只能验签的公钥
+func BLSKeyFromPublicKeyHex(s string) (*BLSKey, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return BLSKeyFromPublicKey(b)
+}
+
+func (c *BLSKey) SecretKey() ([]byte, error) {
+	if c.priv == nil {
+		return nil, ErrNoSecretKey
+	}
+	return c.priv.Serialize(), nil
+}
+
+func (c *BLSKey) SecretKeyHex() (string, error) {
+	if c.priv == nil {
+		return "", ErrNoSecretKey
+	}
+	return c.priv.SerializeToHexStr(), nil
+}
+
+func (c *BLSKey) PublicKey() ([]byte, error) {
+	if c.pub == nil {
+		return nil, ErrNoPublicKey
+	}
+	return c.pub.Serialize(), nil
+}
+
+func (c *BLSKey) PublicKeyHex() (string, error) {
+	if c.pub == nil {
+		return "", ErrNoPublicKey
+	}
+	return c.pub.SerializeToHexStr(), nil
+}
+
+// SignBytes 使用当前 ciphersuite 对任意字节消息签名，返回序列化的签名
+func (c *BLSKey) SignBytes(msg []byte) ([]byte, error) {
+	if err := initMinSignature(); err != nil {
+		return nil, err
+	}
+	if c.priv == nil {
+		return nil, ErrNoSecretKey
+	}
+
+	pub := c.priv.GetPublicKey().Serialize()
+	return c.priv.SignByte(augment(pub, msg)).Serialize(), nil
+}
+
+// VerifyBytes 使用当前 ciphersuite 校验序列化的公钥和签名
+func VerifyBytes(pk, msg, sig []byte) error {
+	pub, err := parsePublicKey(pk)
+	if err != nil {
+		return err
+	}
+
+	var s bls.Sign
+	if err := s.Deserialize(sig); err != nil {
+		return fmt.Errorf("deserialize signature: %w", err)
+	}
+
+	if !s.VerifyByte(pub, augment(pub.Serialize(), msg)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ProofOfPossession 生成私钥的持有证明（对自身公钥的签名），16 进制编码。
+// SuitePoP 下使用 IETF 草案规定的独立 DST
+func (c *BLSKey) ProofOfPossession() (string, error) {
+	if c.priv == nil {
+		return "", ErrNoSecretKey
+	}
+
+	pop, err := popProve(c.priv)
+	if err != nil {
+		return "", err
+	}
+	return pop.SerializeToHexStr(), nil
+}
+
+// VerifyPoP 校验 16 进制编码的公钥和持有证明
+func VerifyPoP(pk, pop string) error {
+	if err := initMinSignature(); err != nil {
+		return err
+	}
+
+	var pub bls.PublicKey
+	if err := pub.DeserializeHexStr(pk); err != nil {
+		return fmt.Errorf("deserialize public key: %w", err)
+	}
+
+	var sig bls.Sign
+	if err := sig.DeserializeHexStr(pop); err != nil {
+		return fmt.Errorf("deserialize proof of possession: %w", err)
+	}
+
+	if pub.IsZero() || !popVerify(&pub, &sig) {
+		return ErrInvalidPoP
+	}
+
+	return nil
+}
+
+// AggPKWithPoP 在校验每个公钥的持有证明后聚合公钥，防止 rogue-key 攻击
+func (c *BLSKey) AggPKWithPoP(pk, pop []string) error {
+	if len(pk) != len(pop) {
+		return ErrLengthMismatch
+	}
+
+	for i := range pk {
+		if err := VerifyPoP(pk[i], pop[i]); err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
+	}
+
+	return c.AggPK(pk)
+}
+
+func popProve(sk *bls.SecretKey) (*bls.Sign, error) {
+	dst := CurrentSuite().PopDST
+	if dst == "" {
+		return sk.GetPop(), nil
+	}
+
+	h, err := hashToG1(sk.GetPublicKey().Serialize(), dst)
+	if err != nil {
+		return nil, err
+	}
+
+	var pop bls.G1
+	bls.G1Mul(&pop, h, bls.CastFromSecretKey(sk))
+	return bls.CastToSign(&pop), nil
+}
+
+func popVerify(pub *bls.PublicKey, pop *bls.Sign) bool {
+	dst := CurrentSuite().PopDST
+	if dst == "" {
+		return pop.VerifyPop(pub)
+	}
+
+	h, err := hashToG1(pub.Serialize(), dst)
+	if err != nil {
+		return false
+	}
+
+	return pairingIsOne(
+		[]bls.G1{*bls.CastFromSign(pop), *h},
+		[]bls.G2{negGenerator(), *bls.CastFromPublicKey(pub)},
+	)
+}
+
+func parsePublicKey(b []byte) (*bls.PublicKey, error) {
+	if err := initMinSignature(); err != nil {
+		return nil, err
+	}
+
+	var pub bls.PublicKey
+	if err := pub.Deserialize(b); err != nil {
+		return nil, fmt.Errorf("deserialize public key: %w", err)
+	}
+	if pub.IsZero() {
+		return nil, ErrZeroKey
+	}
+
+	return &pub, nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode hex: %w", err)
+	}
+	return b, nil
+}
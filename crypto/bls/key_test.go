@@ -0,0 +1,93 @@
+package bls
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateBLSKey(t *testing.T) {
+	key, err := GenerateBLSKey()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	skHex, err := key.SecretKeyHex()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	pkHex, _ := key.PublicKeyHex()
+
+	restored, err := BLSKeyFromSecretKeyHex(skHex)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if restoredPK, _ := restored.PublicKeyHex(); restoredPK != pkHex {
+		t.Fatalf("bad public key: %s %s", restoredPK, pkHex)
+	}
+
+	pkBytes, _ := key.PublicKey()
+	verifier, err := BLSKeyFromPublicKey(pkBytes)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := verifier.Sign("test"); err == nil {
+		t.Fatalf("public-only key must not sign")
+	}
+	if _, err := verifier.SecretKey(); !errors.Is(err, ErrNoSecretKey) {
+		t.Fatalf("expect ErrNoSecretKey, got %v", err)
+	}
+}
+
+func TestBLSKey_invalid(t *testing.T) {
+	if _, err := BLSKeyFromSecretKeyHex("zz"); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	if _, err := BLSKeyFromPublicKey([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	var key BLSKey
+	if err := key.AggPK([]string{"not hex"}); err == nil {
+		t.Fatalf("expect error")
+	}
+	if err := key.AggSK([]string{"00"}); err == nil {
+		t.Fatalf("expect error")
+	}
+}
+
+func TestProofOfPossession(t *testing.T) {
+	var pks, pops []string
+	for i := 0; i < 3; i++ {
+		key, _ := GenerateBLSKey()
+
+		pk, _ := key.PublicKeyHex()
+		pop, err := key.ProofOfPossession()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		if err := VerifyPoP(pk, pop); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		pks = append(pks, pk)
+		pops = append(pops, pop)
+	}
+
+	var agg BLSKey
+	if err := agg.AggPKWithPoP(pks, pops); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A proof for a different key must be rejected
+	pops[0], pops[1] = pops[1], pops[0]
+	if err := agg.AggPKWithPoP(pks, pops); !errors.Is(err, ErrInvalidPoP) {
+		t.Fatalf("expect ErrInvalidPoP, got %v", err)
+	}
+
+	if err := agg.AggPKWithPoP(pks, pops[:2]); !errors.Is(err, ErrLengthMismatch) {
+		t.Fatalf("expect ErrLengthMismatch, got %v", err)
+	}
+}
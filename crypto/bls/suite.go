@@ -0,0 +1,180 @@
+package bls
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+// Variant 决定公钥和签名分别位于哪个群
+type Variant int
+
+const (
+	// MinSignatureSize 签名在 G1（48 字节），公钥在 G2（96 字节），BLSKey 使用该布局
+	MinSignatureSize Variant = iota
+	// MinPubkeySize 公钥在 G1（48 字节），签名在 G2（96 字节），以太坊使用该布局，见 EthKey
+	MinPubkeySize
+)
+
+// Scheme 是 IETF BLS 草案中防御 rogue-key 攻击的三种方案
+type Scheme int
+
+const (
+	// SchemeBasic 要求聚合签名中的消息互不相同
+	SchemeBasic Scheme = iota
+	// SchemeAug 签名前在消息前拼接签名者公钥
+	SchemeAug
+	// SchemePoP 要求公钥附带持有证明，同一消息的聚合签名可以快速校验
+	SchemePoP
+)
+
+// Ciphersuite 描述签名使用的曲线布局、方案和 hash-to-curve 的 DST
+type Ciphersuite struct {
+	ID      string
+	Variant Variant
+	Scheme  Scheme
+
+	// PopDST 是 SchemePoP 生成持有证明时使用的 DST
+	PopDST string
+
+	legacy bool
+}
+
+var (
+	// SuiteLegacy 是 herumi 的默认设置，与之前版本生成的密钥和签名兼容，
+	// 但 hash-to-curve、生成元和序列化格式都与其他 BLS 库不同
+	SuiteLegacy = &Ciphersuite{
+		ID:     "herumi-legacy",
+		Scheme: SchemePoP,
+		legacy: true,
+	}
+
+	SuiteBasic = &Ciphersuite{
+		ID:     "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_",
+		Scheme: SchemeBasic,
+	}
+
+	SuiteAug = &Ciphersuite{
+		ID:     "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_AUG_",
+		Scheme: SchemeAug,
+	}
+
+	SuitePoP = &Ciphersuite{
+		ID:     "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_",
+		Scheme: SchemePoP,
+		PopDST: "BLS_POP_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_",
+	}
+
+	// SuiteEthereum 与以太坊共识层的签名兼容
+	SuiteEthereum = &Ciphersuite{
+		ID:      "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_",
+		Variant: MinPubkeySize,
+		Scheme:  SchemePoP,
+		PopDST:  "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_",
+	}
+)
+
+// IETF 草案（draft-irtf-cfrg-pairing-friendly-curves）规定的生成元，与 herumi 的默认值不同
+const (
+	ietfG1Generator = "1 0x17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb " +
+		"0x08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1"
+	ietfG2Generator = "1 0x24aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8 " +
+		"0x13e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e " +
+		"0x0ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801 " +
+		"0x0606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be"
+
+	// legacyMapToMode 是 herumi 初始化后的默认 map-to-curve 模式
+	legacyMapToMode = 0
+)
+
+var ErrUnsupportedSuite = errors.New("operation not supported by the configured ciphersuite")
+
+var (
+	suiteMu     sync.RWMutex
+	active      = SuiteLegacy
+	legacyG2Gen bls.PublicKey
+	ietfG1Gen   bls.G1
+)
+
+// Configure 切换进程内所有 BLS 操作使用的 ciphersuite，默认为 SuiteLegacy。
+// 底层库的设置是全局的，应在启动时调用一次，不能与其他 BLS 操作并发执行；
+// 切换后，之前在其他 ciphersuite 下生成的公钥和签名不再有效
+func Configure(suite *Ciphersuite) error {
+	if err := Init(); err != nil {
+		return err
+	}
+
+	suiteMu.Lock()
+	defer suiteMu.Unlock()
+
+	if err := applySuite(suite); err != nil {
+		// 恢复到切换前的设置，避免停留在不一致的状态
+		applySuite(active)
+		return err
+	}
+
+	active = suite
+	return nil
+}
+
+// CurrentSuite 返回当前生效的 ciphersuite
+func CurrentSuite() *Ciphersuite {
+	suiteMu.RLock()
+	defer suiteMu.RUnlock()
+	return active
+}
+
+func applySuite(suite *Ciphersuite) error {
+	if suite == nil {
+		return ErrUnsupportedSuite
+	}
+
+	if suite.legacy {
+		bls.SetETHserialization(false)
+		if err := bls.SetMapToMode(legacyMapToMode); err != nil {
+			return err
+		}
+		return bls.SetGeneratorOfPublicKey(&legacyG2Gen)
+	}
+
+	bls.SetETHserialization(true)
+	if err := bls.SetMapToMode(bls.IRTF); err != nil {
+		return err
+	}
+
+	var gen bls.PublicKey
+	if err := gen.SetHexString(ietfG2Generator); err != nil {
+		return err
+	}
+	if err := bls.SetGeneratorOfPublicKey(&gen); err != nil {
+		return err
+	}
+
+	if suite.Variant == MinPubkeySize {
+		return bls.SetDstG2(suite.ID)
+	}
+	return bls.SetDstG1(suite.ID)
+}
+
+// initMinSignature 供 BLSKey 相关的函数使用，公钥在 G1 的 ciphersuite 下返回 ErrUnsupportedSuite
+func initMinSignature() error {
+	if err := Init(); err != nil {
+		return err
+	}
+	if CurrentSuite().Variant != MinSignatureSize {
+		return ErrUnsupportedSuite
+	}
+	return nil
+}
+
+// augment 在 SchemeAug 下把公钥拼接到消息之前，其他方案原样返回
+func augment(pub, msg []byte) []byte {
+	if CurrentSuite().Scheme != SchemeAug {
+		return msg
+	}
+
+	out := make([]byte, 0, len(pub)+len(msg))
+	out = append(out, pub...)
+	return append(out, msg...)
+}
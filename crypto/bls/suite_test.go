@@ -0,0 +1,179 @@
+package bls
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// useSuite 切换 ciphersuite，测试结束后恢复默认设置
+func useSuite(t *testing.T, suite *Ciphersuite) {
+	t.Helper()
+
+	if err := Configure(suite); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := Configure(SuiteLegacy); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	})
+}
+
+func TestConfigure_legacy(t *testing.T) {
+	key, err := GenerateBLSKey()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	before, _ := key.Sign("message")
+
+	if err := Configure(SuitePoP); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	during, _ := key.Sign("message")
+
+	if err := Configure(SuiteLegacy); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	after, _ := key.Sign("message")
+
+	if before == during || before != after {
+		t.Fatalf("bad: %s %s %s", before, during, after)
+	}
+	if CurrentSuite() != SuiteLegacy {
+		t.Fatalf("bad: %s", CurrentSuite().ID)
+	}
+
+	if err := Configure(nil); !errors.Is(err, ErrUnsupportedSuite) {
+		t.Fatalf("expect ErrUnsupportedSuite, got %v", err)
+	}
+}
+
+func TestSuiteBasic_vector(t *testing.T) {
+	useSuite(t, SuiteBasic)
+
+	// https://github.com/dfinity/agent-js/blob/5214dc1fc4b9b41f023a88b1228f04d2f2536987/packages/bls-verify/src/index.test.ts#L101
+	pk, _ := hex.DecodeString("a7623a93cdb56c4d23d99c14216afaab3dfd6d4f9eb3db23d038280b6d5cb2caaee2a19dd92c9df7001dede23bf036bc0f33982dfb41e8fa9b8e96b5dc3e83d55ca4dd146c7eb2e8b6859cb5a5db815db86810b8d12cee1588b5dbf34a4dc9a5")
+	sig, _ := hex.DecodeString("b89e13a212c830586eaa9ad53946cd968718ebecc27eda849d9232673dcd4f440e8b5df39bf14a88048c15e16cbcaabe")
+
+	if err := VerifyBytes(pk, []byte("hello"), sig); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := VerifyBytes(pk, []byte("hallo"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestSuites(t *testing.T) {
+	for _, suite := range []*Ciphersuite{SuiteLegacy, SuiteBasic, SuiteAug, SuitePoP} {
+		t.Run(suite.ID, func(t *testing.T) {
+			useSuite(t, suite)
+
+			key, err := GenerateBLSKey()
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			pk, _ := key.PublicKey()
+
+			msg := []byte{0x00, 0xff, 'b', 'l', 's'}
+			sig, err := key.SignBytes(msg)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if err := VerifyBytes(pk, msg, sig); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if err := VerifyBytes(pk, msg[1:], sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expect ErrInvalidSignature, got %v", err)
+			}
+
+			// Sign and SignBytes agree, and the hex output round-trips
+			sigHex, _ := key.Sign(string(msg))
+			if sigHex != hex.EncodeToString(sig) {
+				t.Fatalf("bad: %s", sigHex)
+			}
+
+			// The augmented scheme signs pk || msg
+			raw := key.priv.SignByte(msg).Serialize()
+			if (suite.Scheme == SchemeAug) == (hex.EncodeToString(raw) == sigHex) {
+				t.Fatalf("unexpected augmentation for %s", suite.ID)
+			}
+
+			pkHex, _ := key.PublicKeyHex()
+			pop, err := key.ProofOfPossession()
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if err := VerifyPoP(pkHex, pop); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			// The IETF PoP suite uses its own DST for proofs
+			if suite.PopDST != "" && pop == key.priv.GetPop().SerializeToHexStr() {
+				t.Fatalf("proof of possession uses the signing dst")
+			}
+
+			err = FastAggregateVerify([]string{pkHex}, string(msg), sigHex)
+			if suite.Scheme == SchemePoP && err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if suite.Scheme != SchemePoP && !errors.Is(err, ErrUnsupportedSuite) {
+				t.Fatalf("expect ErrUnsupportedSuite, got %v", err)
+			}
+
+			group, shares, err := DealThresholdKey(2, []uint64{1, 2, 3})
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			p1, _ := shares[0].SignPartial("threshold")
+			p3, _ := shares[2].SignPartial("threshold")
+			groupSig, err := group.Recover("threshold", []*PartialSignature{p1, p3})
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			// The group signature is a plain signature under the group public key
+			groupPK, _ := hex.DecodeString(group.PublicKeyHex())
+			groupSigRaw, _ := hex.DecodeString(groupSig)
+			if err := VerifyBytes(groupPK, []byte("threshold"), groupSigRaw); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+
+			if err := BatchVerify([]*SignedMessage{
+				{PublicKey: pkHex, Message: string(msg), Signature: sigHex},
+				{PublicKey: group.PublicKeyHex(), Message: "threshold", Signature: groupSig},
+			}); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+		})
+	}
+}
+
+func TestSuiteAug_sameMessage(t *testing.T) {
+	useSuite(t, SuiteAug)
+
+	keys := signers(t, 3)
+	var pks, msgs, sigs []string
+	for _, k := range keys {
+		pk, _ := k.PublicKeyHex()
+		sig, _ := k.Sign("same")
+		pks, msgs, sigs = append(pks, pk), append(msgs, "same"), append(sigs, sig)
+	}
+
+	// Augmentation makes identical messages distinct
+	agg, _ := AggregateSignatures(sigs)
+	if err := AggregateVerify(pks, msgs, agg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestSuiteEthereum_minSignatureUnsupported(t *testing.T) {
+	useSuite(t, SuiteEthereum)
+
+	if _, err := GenerateBLSKey(); !errors.Is(err, ErrUnsupportedSuite) {
+		t.Fatalf("expect ErrUnsupportedSuite, got %v", err)
+	}
+	if _, _, err := DealThresholdKey(2, []uint64{1, 2}); !errors.Is(err, ErrUnsupportedSuite) {
+		t.Fatalf("expect ErrUnsupportedSuite, got %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+var (
+	ErrInvalidThreshold  = errors.New("threshold must be between 1 and the number of participants")
+	ErrInvalidID         = errors.New("participant id must be non-zero and unique")
+	ErrNotEnoughShares   = errors.New("not enough valid partial signatures")
+	ErrInvalidPartialSig = errors.New("invalid partial signature")
+)
+
+// ThresholdGroup 是 t-of-n 门限密钥的公开部分。
+// 私钥作为 t-1 次多项式的常数项被分片给各参与方，
+// 每个参与方用自己的分片签名，任意 t 个部分签名通过拉格朗日插值
+// 恢复出组签名，整个过程不需要重新拼出私钥。
+// verification 为多项式系数对应的公钥（Feldman 承诺），第 0 项即组公钥
+type ThresholdGroup struct {
+	Threshold int
+
+	verification []bls.PublicKey
+}
+
+// KeyShare 是某个参与方持有的私钥分片
+type KeyShare struct {
+	ID    uint64
+	Group *ThresholdGroup
+
+	priv *bls.SecretKey
+}
+
+// PartialSignature 是某个参与方对消息的部分签名，16 进制编码
+type PartialSignature struct {
+	ID        uint64
+	Signature string
+}
+
+// DealThresholdKey 生成新的门限密钥并分发给 ids 对应的参与方，
+// 生成后完整私钥立即丢弃。需要没有可信分发者时请使用 dkg 包
+func DealThresholdKey(threshold int, ids []uint64) (*ThresholdGroup, []*KeyShare, error) {
+	key, err := GenerateBLSKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer key.priv.SetLittleEndian(make([]byte, 32))
+
+	return key.SplitThreshold(threshold, ids)
+}
+
+// SplitThreshold 将已有私钥分片为 t-of-n 门限密钥，组公钥与原公钥相同
+func (c *BLSKey) SplitThreshold(threshold int, ids []uint64) (*ThresholdGroup, []*KeyShare, error) {
+	if c.priv == nil {
+		return nil, nil, ErrNoSecretKey
+	}
+	if threshold < 1 || threshold > len(ids) {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	blsIDs, err := toIDs(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msk := c.priv.GetMasterSecretKey(threshold)
+	defer func() {
+		// 清除多项式系数，只留下分片
+		for i := range msk {
+			msk[i].SetLittleEndian(make([]byte, 32))
+		}
+	}()
+
+	group := &ThresholdGroup{
+		Threshold:    threshold,
+		verification: bls.GetMasterPublicKey(msk),
+	}
+
+	shares := make([]*KeyShare, len(ids))
+	for i := range ids {
+		var sk bls.SecretKey
+		if err := sk.Set(msk, &blsIDs[i]); err != nil {
+			return nil, nil, err
+		}
+
+		shares[i] = &KeyShare{
+			ID:    ids[i],
+			Group: group,
+			priv:  &sk,
+		}
+	}
+
+	return group, shares, nil
+}
+
+// NewThresholdGroup 从 16 进制的验证向量恢复门限组的公开部分
+func NewThresholdGroup(verification []string) (*ThresholdGroup, error) {
+	if err := initMinSignature(); err != nil {
+		return nil, err
+	}
+	if len(verification) == 0 {
+		return nil, ErrInvalidThreshold
+	}
+
+	group := &ThresholdGroup{
+		Threshold:    len(verification),
+		verification: make([]bls.PublicKey, len(verification)),
+	}
+
+	for i, v := range verification {
+		if err := group.verification[i].DeserializeHexStr(v); err != nil {
+			return nil, fmt.Errorf("verification vector %d: %w", i, err)
+		}
+	}
+
+	return group, nil
+}
+
+// NewKeyShare 从 16 进制私钥分片恢复 KeyShare，并校验分片与验证向量一致
+func NewKeyShare(id uint64, secretHex string, group *ThresholdGroup) (*KeyShare, error) {
+	if err := initMinSignature(); err != nil {
+		return nil, err
+	}
+
+	var sk bls.SecretKey
+	if err := sk.DeserializeHexStr(secretHex); err != nil {
+		return nil, fmt.Errorf("deserialize secret key: %w", err)
+	}
+
+	expected, err := group.sharePublicKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if !sk.GetPublicKey().IsEqual(expected) {
+		return nil, fmt.Errorf("key share %d does not match the verification vector", id)
+	}
+
+	return &KeyShare{
+		ID:    id,
+		Group: group,
+		priv:  &sk,
+	}, nil
+}
+
+// PublicKeyHex 返回组公钥
+func (g *ThresholdGroup) PublicKeyHex() string {
+	return g.verification[0].SerializeToHexStr()
+}
+
+// VerificationVector 返回 16 进制编码的验证向量，可公开发布
+func (g *ThresholdGroup) VerificationVector() []string {
+	out := make([]string, len(g.verification))
+	for i := range g.verification {
+		out[i] = g.verification[i].SerializeToHexStr()
+	}
+	return out
+}
+
+// SharePublicKeyHex 返回参与方 id 的公钥分片，用于校验其部分签名
+func (g *ThresholdGroup) SharePublicKeyHex(id uint64) (string, error) {
+	pub, err := g.sharePublicKey(id)
+	if err != nil {
+		return "", err
+	}
+	return pub.SerializeToHexStr(), nil
+}
+
+// VerifyPartial 校验单个部分签名
+func (g *ThresholdGroup) VerifyPartial(msg string, partial *PartialSignature) error {
+	pub, err := g.sharePublicKey(partial.ID)
+	if err != nil {
+		return err
+	}
+
+	var sig bls.Sign
+	if err := sig.DeserializeHexStr(partial.Signature); err != nil {
+		return fmt.Errorf("%w: participant %d: %v", ErrInvalidPartialSig, partial.ID, err)
+	}
+	if !sig.VerifyByte(pub, g.augment(msg)) {
+		return fmt.Errorf("%w: participant %d", ErrInvalidPartialSig, partial.ID)
+	}
+
+	return nil
+}
+
+// Recover 从部分签名中恢复组签名。无效的部分签名会被跳过，
+// 有效签名不足 Threshold 个时返回 ErrNotEnoughShares
+func (g *ThresholdGroup) Recover(msg string, partials []*PartialSignature) (string, error) {
+	var (
+		sigs []bls.Sign
+		ids  []bls.ID
+		bad  []uint64
+		seen = map[uint64]bool{}
+	)
+
+	for _, p := range partials {
+		if seen[p.ID] {
+			continue
+		}
+		if err := g.VerifyPartial(msg, p); err != nil {
+			bad = append(bad, p.ID)
+			continue
+		}
+		seen[p.ID] = true
+
+		var sig bls.Sign
+		sig.DeserializeHexStr(p.Signature)
+
+		id, _ := ParticipantID(p.ID)
+		sigs = append(sigs, sig)
+		ids = append(ids, id)
+
+		if len(sigs) == g.Threshold {
+			break
+		}
+	}
+
+	if len(sigs) < g.Threshold {
+		return "", fmt.Errorf("%w: have %d, need %d, invalid from %v", ErrNotEnoughShares, len(sigs), g.Threshold, bad)
+	}
+
+	var sig bls.Sign
+	if err := sig.Recover(sigs, ids); err != nil {
+		return "", err
+	}
+
+	return sig.SerializeToHexStr(), nil
+}
+
+// Verify 使用组公钥校验组签名
+func (g *ThresholdGroup) Verify(msg, sig string) bool {
+	var s bls.Sign
+	if err := s.DeserializeHexStr(sig); err != nil {
+		return false
+	}
+	return s.VerifyByte(&g.verification[0], g.augment(msg))
+}
+
+// augment 在 SchemeAug 下拼接组公钥，部分签名和组签名都对同一条消息签名
+func (g *ThresholdGroup) augment(msg string) []byte {
+	return augment(g.verification[0].Serialize(), []byte(msg))
+}
+
+func (g *ThresholdGroup) sharePublicKey(id uint64) (*bls.PublicKey, error) {
+	blsID, err := ParticipantID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var pub bls.PublicKey
+	if err := pub.Set(g.verification, &blsID); err != nil {
+		return nil, err
+	}
+	return &pub, nil
+}
+
+// SignPartial 使用私钥分片对消息签名
+func (s *KeyShare) SignPartial(msg string) (*PartialSignature, error) {
+	if s.priv == nil {
+		return nil, ErrNoSecretKey
+	}
+
+	return &PartialSignature{
+		ID:        s.ID,
+		Signature: s.priv.SignByte(s.Group.augment(msg)).SerializeToHexStr(),
+	}, nil
+}
+
+// SecretKeyHex 导出私钥分片，用于持久化
+func (s *KeyShare) SecretKeyHex() (string, error) {
+	if s.priv == nil {
+		return "", ErrNoSecretKey
+	}
+	return s.priv.SerializeToHexStr(), nil
+}
+
+func (s *KeyShare) PublicKeyHex() string {
+	return s.priv.GetPublicKey().SerializeToHexStr()
+}
+
+func toIDs(ids []uint64) ([]bls.ID, error) {
+	seen := map[uint64]bool{}
+	out := make([]bls.ID, len(ids))
+
+	for i, v := range ids {
+		if seen[v] {
+			return nil, ErrInvalidID
+		}
+		seen[v] = true
+
+		id, err := ParticipantID(v)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = id
+	}
+
+	return out, nil
+}
+
+// ParticipantID 把门限方案和 DKG 中的参与方编号转换为 bls.ID，编号不能为 0
+func ParticipantID(v uint64) (bls.ID, error) {
+	var id bls.ID
+	if v == 0 {
+		return id, ErrInvalidID
+	}
+
+	err := id.SetDecString(strconv.FormatUint(v, 10))
+	return id, err
+}
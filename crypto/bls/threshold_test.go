@@ -0,0 +1,136 @@
+package bls
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestThreshold(t *testing.T) {
+	ids := []uint64{1, 2, 3, 4, 5}
+	group, shares, err := DealThresholdKey(3, ids)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	message := "Hello, Threshold Signature!"
+
+	partials := make([]*PartialSignature, len(shares))
+	for i, s := range shares {
+		if partials[i], err = s.SignPartial(message); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if err := group.VerifyPartial(message, partials[i]); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	// Any 3 of the 5 partial signatures recover the same group signature
+	var expected string
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			for k := j + 1; k < 5; k++ {
+				sig, err := group.Recover(message, []*PartialSignature{partials[i], partials[j], partials[k]})
+				if err != nil {
+					t.Fatalf("err: %v", err)
+				}
+				if !group.Verify(message, sig) {
+					t.Fatalf("(%d,%d,%d): group signature invalid", i, j, k)
+				}
+
+				if expected == "" {
+					expected = sig
+				} else if sig != expected {
+					t.Fatalf("(%d,%d,%d): group signature differs", i, j, k)
+				}
+			}
+		}
+	}
+
+	if _, err := group.Recover(message, partials[:2]); !errors.Is(err, ErrNotEnoughShares) {
+		t.Fatalf("expect ErrNotEnoughShares, got %v", err)
+	}
+
+	// Duplicate partials do not count twice
+	dup := []*PartialSignature{partials[0], partials[0], partials[1]}
+	if _, err := group.Recover(message, dup); !errors.Is(err, ErrNotEnoughShares) {
+		t.Fatalf("expect ErrNotEnoughShares, got %v", err)
+	}
+}
+
+func TestThreshold_badPartial(t *testing.T) {
+	group, shares, err := DealThresholdKey(2, []uint64{10, 20, 30})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	message := "test"
+	good, _ := shares[0].SignPartial(message)
+	other, _ := shares[1].SignPartial("other message")
+	third, _ := shares[2].SignPartial(message)
+
+	if err := group.VerifyPartial(message, other); !errors.Is(err, ErrInvalidPartialSig) {
+		t.Fatalf("expect ErrInvalidPartialSig, got %v", err)
+	}
+
+	// The bad partial is skipped and the remaining two suffice
+	sig, err := group.Recover(message, []*PartialSignature{other, good, third})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !group.Verify(message, sig) {
+		t.Fatalf("group signature invalid")
+	}
+
+	if _, err := group.Recover(message, []*PartialSignature{other, good}); !errors.Is(err, ErrNotEnoughShares) {
+		t.Fatalf("expect ErrNotEnoughShares, got %v", err)
+	}
+}
+
+func TestThreshold_existingKey(t *testing.T) {
+	key, _ := GenerateBLSKey()
+	pk, _ := key.PublicKeyHex()
+
+	group, shares, err := key.SplitThreshold(2, []uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if group.PublicKeyHex() != pk {
+		t.Fatalf("group public key differs from the split key")
+	}
+
+	// Persist and restore the public group and one share
+	restored, err := NewThresholdGroup(group.VerificationVector())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	secret, _ := shares[1].SecretKeyHex()
+	share, err := NewKeyShare(2, secret, restored)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := NewKeyShare(3, secret, restored); err == nil {
+		t.Fatalf("expect mismatch error")
+	}
+
+	p1, _ := shares[0].SignPartial("test")
+	p2, _ := share.SignPartial("test")
+	sig, err := restored.Recover("test", []*PartialSignature{p1, p2})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !restored.Verify("test", sig) {
+		t.Fatalf("group signature invalid")
+	}
+
+	if _, _, err := key.SplitThreshold(4, []uint64{1, 2, 3}); !errors.Is(err, ErrInvalidThreshold) {
+		t.Fatalf("expect ErrInvalidThreshold, got %v", err)
+	}
+	if _, _, err := key.SplitThreshold(2, []uint64{1, 1, 3}); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expect ErrInvalidID, got %v", err)
+	}
+	if _, _, err := key.SplitThreshold(2, []uint64{0, 1, 3}); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expect ErrInvalidID, got %v", err)
+	}
+}
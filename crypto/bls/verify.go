@@ -0,0 +1,278 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/herumi/bls-go-binary/bls"
+)
+
+var (
+	ErrEmptyInput        = errors.New("nothing to verify")
+	ErrInvalidSignature  = errors.New("invalid signature")
+	ErrDuplicateMessages = errors.New("aggregate verify requires distinct messages")
+)
+
+// SignedMessage 是一条待校验的签名，公钥和签名为 16 进制编码
+type SignedMessage struct {
+	PublicKey string
+	Message   string
+	Signature string
+}
+
+// BatchError 列出批量校验中无效（包括无法解析）的条目下标，
+// 可以用 errors.Is(err, ErrInvalidSignature) 判断
+type BatchError struct {
+	Invalid []int
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("invalid signature at index %v", e.Invalid)
+}
+
+func (e *BatchError) Unwrap() error {
+	return ErrInvalidSignature
+}
+
+// AggregateSignatures 将 16 进制签名相加为聚合签名
+func AggregateSignatures(sigs []string) (string, error) {
+	if err := initMinSignature(); err != nil {
+		return "", err
+	}
+	if len(sigs) == 0 {
+		return "", ErrEmptyInput
+	}
+
+	var agg bls.Sign
+	for i, sig := range sigs {
+		var s bls.Sign
+		if err := s.DeserializeHexStr(sig); err != nil {
+			return "", fmt.Errorf("signature %d: %w", i, err)
+		}
+		agg.Add(&s)
+	}
+
+	return agg.SerializeToHexStr(), nil
+}
+
+// AggregateVerify 校验聚合签名 sig，第 i 个签名者用 pk[i] 对 msgs[i] 签名。
+// 除 SchemeAug 外消息必须互不相同，否则需要持有证明才能防止 rogue-key 攻击
+func AggregateVerify(pk []string, msgs []string, sig string) error {
+	if err := initMinSignature(); err != nil {
+		return err
+	}
+	if len(pk) != len(msgs) {
+		return ErrLengthMismatch
+	}
+	if len(pk) == 0 {
+		return ErrEmptyInput
+	}
+
+	var s bls.Sign
+	if err := s.DeserializeHexStr(sig); err != nil {
+		return fmt.Errorf("deserialize signature: %w", err)
+	}
+
+	// e(sig, g2) == Π e(H(m_i), pk_i)，即 e(sig, -g2) · Π e(H(m_i), pk_i) == 1
+	g1 := make([]bls.G1, 0, len(pk)+1)
+	g2 := make([]bls.G2, 0, len(pk)+1)
+	g1 = append(g1, *bls.CastFromSign(&s))
+	g2 = append(g2, negGenerator())
+
+	seen := make(map[string]bool, len(msgs))
+	for i := range pk {
+		pub, err := parsePublicKeyHex(pk[i])
+		if err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
+
+		msg := augment(pub.Serialize(), []byte(msgs[i]))
+		if seen[string(msg)] {
+			return ErrDuplicateMessages
+		}
+		seen[string(msg)] = true
+
+		h, err := hashMessage(msg)
+		if err != nil {
+			return err
+		}
+
+		g1 = append(g1, *h)
+		g2 = append(g2, *bls.CastFromPublicKey(pub))
+	}
+
+	if !pairingIsOne(g1, g2) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// FastAggregateVerify 校验所有签名者对同一条消息的聚合签名，只需要两次配对。
+// 只适用于 SchemePoP，公钥必须事先通过 VerifyPoP 校验，否则参与方可以伪造聚合签名
+func FastAggregateVerify(pk []string, msg string, sig string) error {
+	if err := initMinSignature(); err != nil {
+		return err
+	}
+	if CurrentSuite().Scheme != SchemePoP {
+		return ErrUnsupportedSuite
+	}
+	if len(pk) == 0 {
+		return ErrEmptyInput
+	}
+
+	pubs := make([]bls.PublicKey, len(pk))
+	for i := range pk {
+		pub, err := parsePublicKeyHex(pk[i])
+		if err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
+		pubs[i] = *pub
+	}
+
+	var s bls.Sign
+	if err := s.DeserializeHexStr(sig); err != nil {
+		return fmt.Errorf("deserialize signature: %w", err)
+	}
+
+	if !s.FastAggregateVerify(pubs, []byte(msg)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// BatchVerify 一次校验多条独立的签名。每条签名乘以随机系数后合并，
+// 相同消息的公钥先合并，配对次数等于不同消息数加一。
+// 合并校验失败时逐条校验，返回列出全部无效条目的 *BatchError
+func BatchVerify(items []*SignedMessage) error {
+	if err := initMinSignature(); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return ErrEmptyInput
+	}
+
+	var (
+		invalid []int
+		sigs    []bls.G1
+		coeffs  []bls.Fr
+		groups  = map[string]int{}
+		msgs    []string
+		pubs    [][]bls.G2
+		pubCoef [][]bls.Fr
+	)
+
+	for i, item := range items {
+		pub, err := parsePublicKeyHex(item.PublicKey)
+		if err != nil {
+			invalid = append(invalid, i)
+			continue
+		}
+
+		var s bls.Sign
+		if err := s.DeserializeHexStr(item.Signature); err != nil {
+			invalid = append(invalid, i)
+			continue
+		}
+
+		var r bls.Fr
+		for r.IsZero() {
+			r.SetByCSPRNG()
+		}
+
+		sigs = append(sigs, *bls.CastFromSign(&s))
+		coeffs = append(coeffs, r)
+
+		msg := string(augment(pub.Serialize(), []byte(item.Message)))
+		g, ok := groups[msg]
+		if !ok {
+			g = len(msgs)
+			groups[msg] = g
+			msgs = append(msgs, msg)
+			pubs = append(pubs, nil)
+			pubCoef = append(pubCoef, nil)
+		}
+		pubs[g] = append(pubs[g], *bls.CastFromPublicKey(pub))
+		pubCoef[g] = append(pubCoef[g], r)
+	}
+
+	if len(invalid) == 0 {
+		// e(Σ r_i·sig_i, -g2) · Π_m e(H(m), Σ r_i·pk_i) == 1
+		g1 := make([]bls.G1, len(msgs)+1)
+		g2 := make([]bls.G2, len(msgs)+1)
+		bls.G1MulVec(&g1[0], sigs, coeffs)
+		g2[0] = negGenerator()
+
+		for g, m := range msgs {
+			h, err := hashMessage([]byte(m))
+			if err != nil {
+				return err
+			}
+			g1[g+1] = *h
+			bls.G2MulVec(&g2[g+1], pubs[g], pubCoef[g])
+		}
+
+		if pairingIsOne(g1, g2) {
+			return nil
+		}
+	}
+
+	return &BatchError{Invalid: findInvalid(items, invalid)}
+}
+
+// findInvalid 逐条校验，返回全部无效条目的下标（已知无法解析的条目直接计入）
+func findInvalid(items []*SignedMessage, known []int) []int {
+	bad := map[int]bool{}
+	for _, i := range known {
+		bad[i] = true
+	}
+
+	var out []int
+	for i, item := range items {
+		if bad[i] {
+			out = append(out, i)
+			continue
+		}
+
+		pub, _ := parsePublicKeyHex(item.PublicKey)
+		var s bls.Sign
+		s.DeserializeHexStr(item.Signature)
+		if !s.VerifyByte(pub, augment(pub.Serialize(), []byte(item.Message))) {
+			out = append(out, i)
+		}
+	}
+
+	return out
+}
+
+func parsePublicKeyHex(s string) (*bls.PublicKey, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	return parsePublicKey(b)
+}
+
+// hashMessage 使用当前 ciphersuite 的 DST 将消息映射到 G1，与 SignByte 一致
+func hashMessage(msg []byte) (*bls.G1, error) {
+	h := bls.HashAndMapToSignature(msg)
+	if h == nil {
+		return nil, errors.New("hash message to curve failed")
+	}
+	return bls.CastFromSign(h), nil
+}
+
+func negGenerator() bls.G2 {
+	var gen bls.PublicKey
+	bls.GetGeneratorOfPublicKey(&gen)
+
+	var neg bls.G2
+	bls.G2Neg(&neg, bls.CastFromPublicKey(&gen))
+	return neg
+}
+
+func pairingIsOne(g1 []bls.G1, g2 []bls.G2) bool {
+	var e bls.GT
+	bls.MillerLoopVec(&e, g1, g2)
+	bls.FinalExp(&e, &e)
+	return e.IsOne()
+}
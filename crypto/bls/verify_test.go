@@ -0,0 +1,172 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func signers(t *testing.T, n int) []*BLSKey {
+	t.Helper()
+
+	keys := make([]*BLSKey, n)
+	for i := range keys {
+		k, err := GenerateBLSKey()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		keys[i] = k
+	}
+	return keys
+}
+
+func signHex(t *testing.T, k *BLSKey, msg string) string {
+	t.Helper()
+	return k.priv.Sign(msg).SerializeToHexStr()
+}
+
+func aggregateHex(t *testing.T, sigs []string) string {
+	t.Helper()
+
+	agg, err := AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return agg
+}
+
+func TestAggregateVerify(t *testing.T) {
+	keys := signers(t, 4)
+
+	var pks, msgs, sigs []string
+	for i, k := range keys {
+		pk, _ := k.PublicKeyHex()
+		msg := fmt.Sprintf("message %d", i)
+
+		pks = append(pks, pk)
+		msgs = append(msgs, msg)
+		sigs = append(sigs, signHex(t, k, msg))
+	}
+	agg := aggregateHex(t, sigs)
+
+	if err := AggregateVerify(pks, msgs, agg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	swapped := slices.Clone(msgs)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if err := AggregateVerify(pks, swapped, agg); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+
+	dup := slices.Clone(msgs)
+	dup[1] = dup[0]
+	if err := AggregateVerify(pks, dup, agg); !errors.Is(err, ErrDuplicateMessages) {
+		t.Fatalf("expect ErrDuplicateMessages, got %v", err)
+	}
+
+	if err := AggregateVerify(pks, msgs[:3], agg); !errors.Is(err, ErrLengthMismatch) {
+		t.Fatalf("expect ErrLengthMismatch, got %v", err)
+	}
+	if err := AggregateVerify(nil, nil, agg); !errors.Is(err, ErrEmptyInput) {
+		t.Fatalf("expect ErrEmptyInput, got %v", err)
+	}
+	if err := AggregateVerify(pks, msgs, "zz"); err == nil {
+		t.Fatalf("expect error for malformed signature")
+	}
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	keys := signers(t, 3)
+	msg := "Hello, Threshold Signature!"
+
+	var pks, sigs []string
+	for _, k := range keys {
+		pk, _ := k.PublicKeyHex()
+		pks = append(pks, pk)
+		sigs = append(sigs, signHex(t, k, msg))
+	}
+	agg := aggregateHex(t, sigs)
+
+	if err := FastAggregateVerify(pks, msg, agg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !CheckAggSign(pks, msg, sigs) {
+		t.Fatalf("CheckAggSign rejected a valid aggregate")
+	}
+
+	if err := FastAggregateVerify(pks[:2], msg, agg); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+	if err := FastAggregateVerify(pks, "other", agg); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+	if err := FastAggregateVerify(nil, msg, agg); !errors.Is(err, ErrEmptyInput) {
+		t.Fatalf("expect ErrEmptyInput, got %v", err)
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	keys := signers(t, 6)
+
+	// Half of the signers share a message to exercise the grouped path
+	var items []*SignedMessage
+	for i, k := range keys {
+		pk, _ := k.PublicKeyHex()
+		msg := fmt.Sprintf("message %d", i%3)
+		items = append(items, &SignedMessage{PublicKey: pk, Message: msg, Signature: signHex(t, k, msg)})
+	}
+
+	if err := BatchVerify(items); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	bad := make([]*SignedMessage, len(items))
+	for i := range items {
+		c := *items[i]
+		bad[i] = &c
+	}
+	bad[1].Message = "forged"
+	bad[4].Signature = items[5].Signature
+	bad[5].PublicKey = "not hex"
+
+	err := BatchVerify(bad)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect *BatchError, got %v", err)
+	}
+	if !slices.Equal(batchErr.Invalid, []int{1, 4, 5}) {
+		t.Fatalf("bad: %v", batchErr.Invalid)
+	}
+
+	// Swapping signatures between two items keeps the sums equal but must still fail
+	swap := []*SignedMessage{
+		{PublicKey: items[0].PublicKey, Message: items[0].Message, Signature: items[1].Signature},
+		{PublicKey: items[1].PublicKey, Message: items[1].Message, Signature: items[0].Signature},
+	}
+	if err := BatchVerify(swap); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+
+	if err := BatchVerify(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Fatalf("expect ErrEmptyInput, got %v", err)
+	}
+}
+
+func BenchmarkBatchVerify(b *testing.B) {
+	var items []*SignedMessage
+	for i := 0; i < 64; i++ {
+		k, _ := GenerateBLSKey()
+		pk, _ := k.PublicKeyHex()
+		msg := fmt.Sprintf("message %d", i)
+		items = append(items, &SignedMessage{PublicKey: pk, Message: msg, Signature: k.priv.Sign(msg).SerializeToHexStr()})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := BatchVerify(items); err != nil {
+			b.Fatalf("err: %v", err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package chacha
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	ErrAuthFailed      = errors.New("message authentication failed")
+	ErrCipherTextShort = errors.New("cipher text too short")
+)
+
+// Seal 使用 ChaCha20-Poly1305 加密并认证 msg 与附加数据 ad，
+// 输出格式为 nonce(12) || ciphertext || tag(16)。
+// 12 字节随机 nonce 在同一密钥下加密超过约 2^32 条消息后碰撞概率不可忽略，
+// 大量加密请使用 SealX
+func (c *ChaCha20) Seal(msg, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(c.priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(aead, msg, ad)
+}
+
+// Open 解密 Seal 的输出，密文或附加数据被篡改时返回 ErrAuthFailed
+func (c *ChaCha20) Open(sealed, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(c.priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, sealed, ad)
+}
+
+// SealX 使用 XChaCha20-Poly1305 加密，输出格式为 nonce(24) || ciphertext || tag(16)。
+// 24 字节的随机 nonce 可以放心地在同一密钥下大量使用
+func (c *ChaCha20) SealX(msg, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(c.priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(aead, msg, ad)
+}
+
+// OpenX 解密 SealX 的输出
+func (c *ChaCha20) OpenX(sealed, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(c.priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, sealed, ad)
+}
+
+func seal(aead cipher.AEAD, msg, ad []byte) ([]byte, error) {
+	out := make([]byte, aead.NonceSize(), aead.NonceSize()+len(msg)+aead.Overhead())
+	if _, err := rand.Read(out); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, out, msg, ad), nil
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrCipherTextShort
+	}
+
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, body, ad)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,77 @@
+package chacha
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func TestSealOpen(t *testing.T) {
+	key := GenKey()
+	msg := []byte("在Go语言中，你可以使用golang.org/x/crypto库来实现ChaCha20加密算法")
+	ad := []byte("header")
+
+	sealed, err := key.Seal(msg, ad)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(sealed) != chacha20poly1305.NonceSize+len(msg)+chacha20poly1305.Overhead {
+		t.Fatalf("bad length: %d", len(sealed))
+	}
+
+	out, err := key.Open(sealed, ad)
+	if err != nil || !bytes.Equal(out, msg) {
+		t.Fatalf("bad: %s %v", out, err)
+	}
+
+	sealedX, err := key.SealX(msg, ad)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(sealedX) != chacha20poly1305.NonceSizeX+len(msg)+chacha20poly1305.Overhead {
+		t.Fatalf("bad length: %d", len(sealedX))
+	}
+
+	out, err = key.OpenX(sealedX, ad)
+	if err != nil || !bytes.Equal(out, msg) {
+		t.Fatalf("bad: %s %v", out, err)
+	}
+}
+
+func TestOpen_tampered(t *testing.T) {
+	key := GenKey()
+
+	sealed, _ := key.Seal([]byte("test"), []byte("ad"))
+	sealedX, _ := key.SealX([]byte("test"), []byte("ad"))
+
+	for i := range sealed {
+		tampered := append([]byte{}, sealed...)
+		tampered[i] ^= 0x80
+		if _, err := key.Open(tampered, []byte("ad")); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("byte %d: expect ErrAuthFailed, got %v", i, err)
+		}
+	}
+
+	for i := range sealedX {
+		tampered := append([]byte{}, sealedX...)
+		tampered[i] ^= 0x80
+		if _, err := key.OpenX(tampered, []byte("ad")); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("byte %d: expect ErrAuthFailed, got %v", i, err)
+		}
+	}
+
+	if _, err := key.Open(sealed, []byte("other")); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expect ErrAuthFailed, got %v", err)
+	}
+
+	// A ChaCha20-Poly1305 box is not a valid XChaCha20-Poly1305 box
+	if _, err := key.OpenX(sealed, []byte("ad")); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	if _, err := key.Open(sealed[:10], []byte("ad")); !errors.Is(err, ErrCipherTextShort) {
+		t.Fatalf("expect ErrCipherTextShort, got %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package chacha
 import (
 	"crypto/rand"
 	"fmt"
-	"log"
 
 	"golang.org/x/crypto/chacha20"
 	"golang.org/x/crypto/chacha20poly1305"
@@ -24,6 +23,8 @@ func GenKey() ChaCha20 {
 	}
 }
 
+// Encrypt 使用未认证的 ChaCha20 流加密，返回密文和 nonce。
+// 密文被篡改时无法发现，需要完整性请使用 Seal / SealX
 func (c *ChaCha20) Encrypt(msg []byte) ([]byte, []byte, error) {
 	plaintext := []byte(msg)
 
@@ -36,7 +37,7 @@ func (c *ChaCha20) Encrypt(msg []byte) ([]byte, []byte, error) {
 	// 初始化加密流
 	cipher, err := chacha20.NewUnauthenticatedCipher(c.priv, nonce)
 	if err != nil {
-		fmt.Printf("无法初始化ChaCha20: %v \n", err)
+		return nil, nil, err
 	}
 
 	ciphertext := make([]byte, len(plaintext))
@@ -48,7 +49,7 @@ func (c *ChaCha20) Encrypt(msg []byte) ([]byte, []byte, error) {
 func (c *ChaCha20) Decrypt(cipherText []byte, nonce []byte) (string, error) {
 	cipher, err := chacha20.NewUnauthenticatedCipher(c.priv, nonce)
 	if err != nil {
-		log.Fatalf("无法重新初始化ChaCha20: %v", err)
+		return "", err
 	}
 
 	decrypted := make([]byte, len(cipherText))
@@ -0,0 +1,182 @@
+package chacha
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	"tools/crypto/kdf"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	wrapVersion = 0x01
+
+	wrapPassphrase = 0x01
+	wrapRSA        = 0x02
+)
+
+var (
+	ErrWrongKeySize = errors.New("chacha20 key must be 32 bytes")
+	ErrUnknownWrap  = errors.New("unknown wrapped key format")
+	ErrUnwrapFailed = errors.New("failed to unwrap key")
+)
+
+// NewKey 使用 32 字节原始密钥构造 ChaCha20，输入会被复制
+func NewKey(raw []byte) (ChaCha20, error) {
+	if len(raw) != chacha20poly1305.KeySize {
+		return ChaCha20{}, ErrWrongKeySize
+	}
+
+	return ChaCha20{
+		priv: append([]byte{}, raw...),
+	}, nil
+}
+
+// KeyFromHex 从 16 进制字符串导入密钥
+func KeyFromHex(s string) (ChaCha20, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return ChaCha20{}, err
+	}
+	defer clear(raw)
+
+	return NewKey(raw)
+}
+
+// KeyFromBase64 从 base64 字符串导入密钥，带或不带填充均可
+func KeyFromBase64(s string) (ChaCha20, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		if raw, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return ChaCha20{}, err
+		}
+	}
+	defer clear(raw)
+
+	return NewKey(raw)
+}
+
+// Bytes 返回原始密钥的副本
+func (c *ChaCha20) Bytes() []byte {
+	return append([]byte{}, c.priv...)
+}
+
+func (c *ChaCha20) Hex() string {
+	return hex.EncodeToString(c.priv)
+}
+
+func (c *ChaCha20) Base64() string {
+	return base64.RawStdEncoding.EncodeToString(c.priv)
+}
+
+// Close 将内存中的密钥清零，之后该密钥不可再使用
+func (c *ChaCha20) Close() error {
+	clear(c.priv)
+	c.priv = nil
+	return nil
+}
+
+// WrapWithPassphrase 用口令派生的密钥（Argon2id）加密导出本密钥，
+// 输出格式为 version || type || paramsLen(2) || kdf params || XChaCha20-Poly1305(key)
+func (c *ChaCha20) WrapWithPassphrase(passphrase []byte) ([]byte, error) {
+	params, err := kdf.NewParams(kdf.Argon2id)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := params.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte{wrapVersion, wrapPassphrase}
+	header = binary.BigEndian.AppendUint16(header, uint16(len(blob)))
+	header = append(header, blob...)
+
+	kek, err := kekFromPassphrase(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	defer kek.Close()
+
+	sealed, err := kek.SealX(c.priv, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, sealed...), nil
+}
+
+// UnwrapWithPassphrase 导入 WrapWithPassphrase 的输出，口令错误时返回 ErrUnwrapFailed。
+// 成本超出上限的参数返回 kdf.ErrInvalidParams，不会尝试派生
+func UnwrapWithPassphrase(wrapped, passphrase []byte) (ChaCha20, error) {
+	if len(wrapped) < 4 || wrapped[0] != wrapVersion || wrapped[1] != wrapPassphrase {
+		return ChaCha20{}, ErrUnknownWrap
+	}
+
+	paramsLen := int(binary.BigEndian.Uint16(wrapped[2:]))
+	if len(wrapped) < 4+paramsLen {
+		return ChaCha20{}, ErrUnknownWrap
+	}
+
+	params, err := kdf.ParseParams(wrapped[4 : 4+paramsLen])
+	if err != nil {
+		return ChaCha20{}, err
+	}
+
+	kek, err := kekFromPassphrase(passphrase, params)
+	if err != nil {
+		return ChaCha20{}, err
+	}
+	defer kek.Close()
+
+	raw, err := kek.OpenX(wrapped[4+paramsLen:], wrapped[:4+paramsLen])
+	if err != nil {
+		return ChaCha20{}, ErrUnwrapFailed
+	}
+	defer clear(raw)
+
+	return NewKey(raw)
+}
+
+// WrapWithRSA 使用 RSA-OAEP(SHA-256) 公钥加密导出本密钥
+func (c *ChaCha20) WrapWithRSA(pub *rsa.PublicKey) ([]byte, error) {
+	header := []byte{wrapVersion, wrapRSA}
+
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, c.priv, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, wrapped...), nil
+}
+
+// UnwrapWithRSA 使用 RSA 私钥导入 WrapWithRSA 的输出
+func UnwrapWithRSA(wrapped []byte, priv *rsa.PrivateKey) (ChaCha20, error) {
+	if len(wrapped) < 2 || wrapped[0] != wrapVersion || wrapped[1] != wrapRSA {
+		return ChaCha20{}, ErrUnknownWrap
+	}
+
+	raw, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, wrapped[2:], wrapped[:2])
+	if err != nil {
+		return ChaCha20{}, ErrUnwrapFailed
+	}
+	defer clear(raw)
+
+	return NewKey(raw)
+}
+
+func kekFromPassphrase(passphrase []byte, params *kdf.Params) (ChaCha20, error) {
+	raw, err := kdf.DeriveKey(passphrase, params)
+	if err != nil {
+		return ChaCha20{}, err
+	}
+
+	return ChaCha20{priv: raw}, nil
+}
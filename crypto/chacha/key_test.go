@@ -0,0 +1,124 @@
+package chacha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"tools/crypto/kdf"
+)
+
+func TestKeyEncoding(t *testing.T) {
+	key := GenKey()
+
+	fromHex, err := KeyFromHex(key.Hex())
+	if err != nil || !bytes.Equal(fromHex.priv, key.priv) {
+		t.Fatalf("bad hex import: %v", err)
+	}
+
+	fromBase64, err := KeyFromBase64(key.Base64())
+	if err != nil || !bytes.Equal(fromBase64.priv, key.priv) {
+		t.Fatalf("bad base64 import: %v", err)
+	}
+
+	fromBytes, err := NewKey(key.Bytes())
+	if err != nil || !bytes.Equal(fromBytes.priv, key.priv) {
+		t.Fatalf("bad raw import: %v", err)
+	}
+
+	if _, err := NewKey(key.Bytes()[:16]); !errors.Is(err, ErrWrongKeySize) {
+		t.Fatalf("expect ErrWrongKeySize, got %v", err)
+	}
+
+	if _, err := KeyFromHex("zz"); err == nil {
+		t.Fatalf("expect error")
+	}
+}
+
+func TestWrapWithPassphrase(t *testing.T) {
+	key := GenKey()
+
+	wrapped, err := key.WrapWithPassphrase([]byte("correct horse battery staple"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := UnwrapWithPassphrase(wrapped, []byte("correct horse battery staple"))
+	if err != nil || !bytes.Equal(out.priv, key.priv) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	if _, err := UnwrapWithPassphrase(wrapped, []byte("wrong")); !errors.Is(err, ErrUnwrapFailed) {
+		t.Fatalf("expect ErrUnwrapFailed, got %v", err)
+	}
+
+	if _, err := UnwrapWithPassphrase([]byte{0x01, 0x02}, nil); !errors.Is(err, ErrUnknownWrap) {
+		t.Fatalf("expect ErrUnknownWrap, got %v", err)
+	}
+}
+
+func TestUnwrapWithPassphrase_costlyParams(t *testing.T) {
+	salt := bytes.Repeat([]byte{0x42}, 16)
+
+	// scrypt N = 2^24, r = 2^20 and argon2id with 4 GiB of memory
+	scrypt := append([]byte{0x01, byte(kdf.Scrypt), 16}, salt...)
+	scrypt = append(scrypt, 24, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01)
+	argon := append([]byte{0x01, byte(kdf.Argon2id), 16}, salt...)
+	argon = append(argon, 0x00, 0x00, 0x00, 0x01, 0x00, 0x40, 0x00, 0x00, 0x01)
+
+	for _, params := range [][]byte{scrypt, argon} {
+		wrapped := []byte{wrapVersion, wrapPassphrase}
+		wrapped = binary.BigEndian.AppendUint16(wrapped, uint16(len(params)))
+		wrapped = append(wrapped, params...)
+		wrapped = append(wrapped, make([]byte, 72)...)
+
+		if _, err := UnwrapWithPassphrase(wrapped, []byte("pw")); !errors.Is(err, kdf.ErrInvalidParams) {
+			t.Fatalf("expect ErrInvalidParams, got %v", err)
+		}
+	}
+}
+
+func TestWrapWithRSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	key := GenKey()
+
+	wrapped, err := key.WrapWithRSA(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := UnwrapWithRSA(wrapped, priv)
+	if err != nil || !bytes.Equal(out.priv, key.priv) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// Header type must match the unwrap function
+	wrapped[1] = wrapPassphrase
+	if _, err := UnwrapWithRSA(wrapped, priv); !errors.Is(err, ErrUnknownWrap) {
+		t.Fatalf("expect ErrUnknownWrap, got %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	key := GenKey()
+	raw := key.priv
+
+	sealed, _ := key.Seal([]byte("test"), nil)
+
+	key.Close()
+
+	if !bytes.Equal(raw, make([]byte, len(raw))) {
+		t.Fatalf("key material not zeroed: %x", raw)
+	}
+
+	if _, err := key.Open(sealed, nil); err == nil {
+		t.Fatalf("expect error after Close")
+	}
+}
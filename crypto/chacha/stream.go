@@ -0,0 +1,30 @@
+package chacha
+
+import (
+	"io"
+
+	"tools/crypto/stream"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// EncryptStream 以 ChaCha20-Poly1305 分段加密 src 并写入 dst，适用于大文件
+func (c *ChaCha20) EncryptStream(dst io.Writer, src io.Reader) error {
+	return stream.Encrypt(dst, src, c.priv, chacha20poly1305.New)
+}
+
+// DecryptStream 解密 EncryptStream 的输出。
+// 返回错误时已写入 dst 的明文不可信，必须丢弃
+func (c *ChaCha20) DecryptStream(dst io.Writer, src io.Reader) error {
+	return stream.Decrypt(dst, src, c.priv, chacha20poly1305.New)
+}
+
+// NewEncryptWriter 返回加密写入器，写完后必须调用 Close
+func (c *ChaCha20) NewEncryptWriter(dst io.Writer) (io.WriteCloser, error) {
+	return stream.NewWriter(dst, c.priv, chacha20poly1305.New)
+}
+
+// NewDecryptReader 返回解密读取器
+func (c *ChaCha20) NewDecryptReader(src io.Reader) (io.Reader, error) {
+	return stream.NewReader(src, c.priv, chacha20poly1305.New)
+}
@@ -0,0 +1,28 @@
+package chacha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	key := GenKey()
+
+	msg := make([]byte, 200*1024+17)
+	rand.Read(msg)
+
+	var sealed bytes.Buffer
+	if err := key.EncryptStream(&sealed, bytes.NewReader(msg)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := key.DecryptStream(&out, &sealed); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !bytes.Equal(out.Bytes(), msg) {
+		t.Fatalf("bad output")
+	}
+}
@@ -0,0 +1,144 @@
+package jose
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	cekSize   = 32
+	gcmIVSize = 12
+	gcmTagLen = 16
+)
+
+var ErrDecryptionFailed = errors.New("jose: decryption failed")
+
+// Encrypt 使用 RSA-OAEP-256 包装随机的内容密钥，A256GCM 加密 plaintext，
+// 返回紧凑格式的 JWE。key 只需要公钥
+func Encrypt(plaintext []byte, key *JWK) (string, error) {
+	if err := checkKey(RSAOAEP256, key); err != nil {
+		return "", err
+	}
+
+	k, err := key.RSAKey()
+	if err != nil {
+		return "", err
+	}
+
+	cek := make([]byte, cekSize)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return "", err
+	}
+	defer clear(cek)
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, k.PublicKey(), cek, nil)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(&Header{Alg: RSAOAEP256, Enc: A256GCM, Kid: key.Kid})
+	if err != nil {
+		return "", err
+	}
+	protected := b64.EncodeToString(header)
+
+	aead, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcmIVSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+
+	// 头部的 base64url 编码作为附加数据参与认证
+	sealed := aead.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcmTagLen], sealed[len(sealed)-gcmTagLen:]
+
+	return strings.Join([]string{
+		protected,
+		b64.EncodeToString(encryptedKey),
+		b64.EncodeToString(iv),
+		b64.EncodeToString(ciphertext),
+		b64.EncodeToString(tag),
+	}, "."), nil
+}
+
+// Decrypt 解密 Encrypt 产生的 JWE，只接受 RSA-OAEP-256 + A256GCM。
+// 密钥不匹配或任意部分被篡改都返回 ErrDecryptionFailed
+func Decrypt(token string, key *JWK) ([]byte, error) {
+	if err := checkKey(RSAOAEP256, key); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, ErrMalformedToken
+	}
+
+	header, err := parseHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if header.Alg != RSAOAEP256 || header.Enc != A256GCM {
+		return nil, fmt.Errorf("%w: alg %q enc %q", ErrAlgorithmMismatch, header.Alg, header.Enc)
+	}
+	if header.Zip != "" {
+		return nil, fmt.Errorf("%w: zip %q", ErrUnsupportedAlgorithm, header.Zip)
+	}
+
+	var raw [4][]byte
+	for i, p := range parts[1:] {
+		if raw[i], err = b64.DecodeString(p); err != nil {
+			return nil, ErrMalformedToken
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := raw[0], raw[1], raw[2], raw[3]
+	if len(iv) != gcmIVSize || len(tag) != gcmTagLen {
+		return nil, ErrMalformedToken
+	}
+
+	k, err := key.RSAKey()
+	if err != nil {
+		return nil, err
+	}
+	if k.PrivateKey() == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), nil, k.PrivateKey(), encryptedKey, nil)
+	if err != nil || len(cek) != cekSize {
+		return nil, ErrDecryptionFailed
+	}
+	defer clear(cek)
+
+	aead, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,77 @@
+package jose
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := loadTestKey(t)
+	msg := []byte("KFC 疯狂星期四 v我50！")
+
+	token, err := Encrypt(msg, key.Public())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	header, err := ParseHeader(token)
+	if err != nil || header.Alg != RSAOAEP256 || header.Enc != A256GCM || header.Kid != key.Kid {
+		t.Fatalf("bad: %+v %v", header, err)
+	}
+
+	out, err := Decrypt(token, key)
+	if err != nil || string(out) != string(msg) {
+		t.Fatalf("bad: %s %v", out, err)
+	}
+
+	if _, err := Decrypt(token, key.Public()); !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("expect ErrNoPrivateKey, got %v", err)
+	}
+
+	empty, _ := Encrypt(nil, key)
+	if out, err := Decrypt(empty, key); err != nil || len(out) != 0 {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestDecrypt_tampered(t *testing.T) {
+	key := loadTestKey(t)
+
+	token, err := Encrypt([]byte("hello"), key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	parts := strings.Split(token, ".")
+
+	for i := range parts {
+		tampered := append([]string{}, parts...)
+		raw, _ := b64.DecodeString(tampered[i])
+		raw[len(raw)-1] ^= 1
+		tampered[i] = b64.EncodeToString(raw)
+
+		if _, err := Decrypt(strings.Join(tampered, "."), key); err == nil {
+			t.Fatalf("part %d: expect error", i)
+		}
+	}
+
+	// 头部改动后密文认证失败
+	header := b64.EncodeToString([]byte(`{"alg":"RSA-OAEP-256","enc":"A256GCM"}`))
+	if _, err := Decrypt(header+token[strings.IndexByte(token, '.'):], key); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("expect ErrDecryptionFailed, got %v", err)
+	}
+
+	other := b64.EncodeToString([]byte(`{"alg":"RSA-OAEP","enc":"A256GCM"}`))
+	if _, err := Decrypt(other+token[strings.IndexByte(token, '.'):], key); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+
+	if _, err := Decrypt(strings.Join(parts[:4], "."), key); !errors.Is(err, ErrMalformedToken) {
+		t.Fatalf("expect ErrMalformedToken, got %v", err)
+	}
+
+	octKey, _ := NewOctJWK([]byte("0123456789abcdef0123456789abcdef"))
+	if _, err := Decrypt(token, octKey); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+// Package jose 在 crypto 下已有的 RSA / AES 原语之上实现 JOSE 的一个子集：
+//
+//   - JWK（RFC 7517）：RSA 与对称（oct）密钥的导入导出，RFC 7638 指纹作为 kid
+//   - JWS（RFC 7515）紧凑格式：PS512、RS256、HS256
+//   - JWE（RFC 7516）紧凑格式：RSA-OAEP-256 + A256GCM
+//
+// 验签和解密都要求调用方明确给出期望的算法，不信任 token 头部的 alg，
+// 不支持 "none"
+package jose
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	aes "tools/crypto/AES"
+	rsakey "tools/crypto/rsa"
+)
+
+const (
+	KeyTypeRSA = "RSA"
+	KeyTypeOct = "oct"
+
+	// minOctKeySize 是对称密钥的最小长度，与 HS256 的输出长度一致
+	minOctKeySize = 32
+)
+
+var (
+	ErrUnsupportedKeyType = errors.New("jose: unsupported key type")
+	ErrInvalidKey         = errors.New("jose: invalid key")
+	ErrKeyTooShort        = errors.New("jose: symmetric key must be at least 32 bytes")
+	ErrNoPrivateKey       = errors.New("jose: private key required")
+)
+
+var b64 = base64.RawURLEncoding
+
+// JWK 是 JSON Web Key。数值字段按 RFC 7518 保存为 base64url（无填充）
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+
+	// RSA 公钥
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// RSA 私钥
+	D  string `json:"d,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// JWKSet 是 JWK 集合，通常以 JSON 形式发布给验签方
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// NewRSAJWK 导出 RSA 密钥，包含私钥时私钥参数一并导出。kid 为 RFC 7638 指纹
+func NewRSAJWK(key *rsakey.RSAKey) (*JWK, error) {
+	pub := key.PublicKey()
+	if pub == nil {
+		return nil, ErrInvalidKey
+	}
+
+	k := &JWK{
+		Kty: KeyTypeRSA,
+		N:   b64.EncodeToString(pub.N.Bytes()),
+		E:   b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+
+	if priv := key.PrivateKey(); priv != nil {
+		if len(priv.Primes) != 2 {
+			return nil, fmt.Errorf("%w: multi-prime rsa keys are not supported", ErrInvalidKey)
+		}
+
+		priv.Precompute()
+		k.D = b64.EncodeToString(priv.D.Bytes())
+		k.P = b64.EncodeToString(priv.Primes[0].Bytes())
+		k.Q = b64.EncodeToString(priv.Primes[1].Bytes())
+		k.DP = b64.EncodeToString(priv.Precomputed.Dp.Bytes())
+		k.DQ = b64.EncodeToString(priv.Precomputed.Dq.Bytes())
+		k.QI = b64.EncodeToString(priv.Precomputed.Qinv.Bytes())
+	}
+
+	kid, err := k.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	k.Kid = kid
+
+	return k, nil
+}
+
+// NewOctJWK 导出对称密钥，例如 AESKey.StringKey() 或 ChaCha20.Bytes() 的结果
+func NewOctJWK(key []byte) (*JWK, error) {
+	if len(key) < minOctKeySize {
+		return nil, ErrKeyTooShort
+	}
+
+	k := &JWK{Kty: KeyTypeOct, K: b64.EncodeToString(key)}
+
+	kid, err := k.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	k.Kid = kid
+
+	return k, nil
+}
+
+// ParseJWK 解析并校验 JSON 格式的 JWK
+func ParseJWK(data []byte) (*JWK, error) {
+	var k JWK
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	switch k.Kty {
+	case KeyTypeRSA:
+		if _, err := k.RSAKey(); err != nil {
+			return nil, err
+		}
+	case KeyTypeOct:
+		if _, err := k.SymmetricKey(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyType, k.Kty)
+	}
+
+	return &k, nil
+}
+
+// Marshal 将 JWK 编码为 JSON
+func (k *JWK) Marshal() ([]byte, error) {
+	return json.Marshal(k)
+}
+
+// Public 返回去掉私钥参数的副本，对称密钥没有公开部分，返回 nil
+func (k *JWK) Public() *JWK {
+	if k.Kty != KeyTypeRSA {
+		return nil
+	}
+
+	return &JWK{Kty: k.Kty, Use: k.Use, Alg: k.Alg, Kid: k.Kid, N: k.N, E: k.E}
+}
+
+// IsPrivate 判断 JWK 是否包含私钥或对称密钥
+func (k *JWK) IsPrivate() bool {
+	return k.D != "" || k.K != ""
+}
+
+// RSAKey 将 JWK 转换为 RSAKey，只含公钥参数时返回的 RSAKey 只能验签和加密
+func (k *JWK) RSAKey() (*rsakey.RSAKey, error) {
+	if k.Kty != KeyTypeRSA {
+		return nil, fmt.Errorf("%w: expect RSA, got %q", ErrUnsupportedKeyType, k.Kty)
+	}
+
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("%w: bad public exponent", ErrInvalidKey)
+	}
+
+	pub := rsa.PublicKey{N: n, E: int(e.Int64())}
+	if k.D == "" {
+		return rsakey.NewRSAPublicKey(&pub), nil
+	}
+
+	priv := &rsa.PrivateKey{PublicKey: pub}
+	if priv.D, err = decodeInt(k.D); err != nil {
+		return nil, err
+	}
+
+	p, err := decodeInt(k.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeInt(k.Q)
+	if err != nil {
+		return nil, err
+	}
+	priv.Primes = []*big.Int{p, q}
+
+	// dp、dq、qi 由 Precompute 重新计算，Validate 校验 n = p*q 以及 d 与 e 匹配
+	if err := priv.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	priv.Precompute()
+
+	return rsakey.NewRSAKey(priv), nil
+}
+
+// SymmetricKey 返回对称密钥的原始字节
+func (k *JWK) SymmetricKey() ([]byte, error) {
+	if k.Kty != KeyTypeOct {
+		return nil, fmt.Errorf("%w: expect oct, got %q", ErrUnsupportedKeyType, k.Kty)
+	}
+
+	key, err := b64.DecodeString(k.K)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if len(key) < minOctKeySize {
+		return nil, ErrKeyTooShort
+	}
+
+	return key, nil
+}
+
+// AESKey 将 32 字节的对称 JWK 转换为 AESKey
+func (k *JWK) AESKey() (*aes.AESKey, error) {
+	key, err := k.SymmetricKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return aes.NewAESKey(key)
+}
+
+// Thumbprint 计算 RFC 7638 的 SHA-256 指纹（base64url）
+func (k *JWK) Thumbprint() (string, error) {
+	// 必需成员按字典序排列，没有多余空白
+	var members string
+	switch k.Kty {
+	case KeyTypeRSA:
+		if k.N == "" || k.E == "" {
+			return "", ErrInvalidKey
+		}
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case KeyTypeOct:
+		if k.K == "" {
+			return "", ErrInvalidKey
+		}
+		members = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, k.K)
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedKeyType, k.Kty)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return b64.EncodeToString(sum[:]), nil
+}
+
+// Key 按 kid 查找密钥，找不到时返回 nil
+func (s *JWKSet) Key(kid string) *JWK {
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k
+		}
+	}
+
+	return nil
+}
+
+// Public 返回只包含公钥的集合，对称密钥会被丢弃
+func (s *JWKSet) Public() *JWKSet {
+	out := &JWKSet{Keys: []*JWK{}}
+	for _, k := range s.Keys {
+		if pub := k.Public(); pub != nil {
+			out.Keys = append(out.Keys, pub)
+		}
+	}
+
+	return out
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	raw, err := b64.DecodeString(s)
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("%w: bad integer %q", ErrInvalidKey, s)
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,138 @@
+package jose
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	rsakey "tools/crypto/rsa"
+)
+
+func loadTestKey(t *testing.T) *JWK {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/rsa.json")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	key, err := ParseJWK(data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return key
+}
+
+func TestRSAJWK(t *testing.T) {
+	key := loadTestKey(t)
+	if !key.IsPrivate() {
+		t.Fatalf("bad: expect private key")
+	}
+
+	kid, err := key.Thumbprint()
+	if err != nil || kid != "E53SewjFiwVSK57bFEopKPpoGbQ6u1VzUL0oXvu54xY" || kid != key.Kid {
+		t.Fatalf("bad: %s %v", kid, err)
+	}
+
+	k, err := key.RSAKey()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	exported, err := NewRSAJWK(k)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if *exported != *key {
+		t.Fatalf("bad: %+v", exported)
+	}
+
+	pub := key.Public()
+	if pub.IsPrivate() || pub.Kid != key.Kid {
+		t.Fatalf("bad: %+v", pub)
+	}
+	pk, err := pub.RSAKey()
+	if err != nil || pk.PrivateKey() != nil || !pk.PublicKey().Equal(k.PublicKey()) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// 从公钥 RSAKey 导出时没有私钥参数
+	fromPub, _ := NewRSAJWK(rsakey.NewRSAPublicKey(k.PublicKey()))
+	if *fromPub != *pub {
+		t.Fatalf("bad: %+v", fromPub)
+	}
+}
+
+func TestRSAJWK_invalid(t *testing.T) {
+	key := loadTestKey(t)
+
+	broken := *key
+	broken.D = broken.P
+	if _, err := broken.RSAKey(); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expect ErrInvalidKey, got %v", err)
+	}
+
+	broken = *key
+	broken.N = "!!"
+	data, _ := broken.Marshal()
+	if _, err := ParseJWK(data); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expect ErrInvalidKey, got %v", err)
+	}
+
+	if _, err := ParseJWK([]byte(`{"kty":"EC","crv":"P-256"}`)); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Fatalf("expect ErrUnsupportedKeyType, got %v", err)
+	}
+	if _, err := key.SymmetricKey(); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Fatalf("expect ErrUnsupportedKeyType, got %v", err)
+	}
+}
+
+func TestOctJWK(t *testing.T) {
+	raw := bytes.Repeat([]byte{0x42}, 32)
+
+	key, err := NewOctJWK(raw)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if key.Public() != nil || !key.IsPrivate() {
+		t.Fatalf("bad: %+v", key)
+	}
+
+	data, _ := key.Marshal()
+	parsed, err := ParseJWK(data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	got, err := parsed.SymmetricKey()
+	if err != nil || !bytes.Equal(got, raw) {
+		t.Fatalf("bad: %x %v", got, err)
+	}
+
+	aesKey, err := parsed.AESKey()
+	if err != nil || aesKey.StringKey() != string(raw) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	if _, err := NewOctJWK(raw[:16]); !errors.Is(err, ErrKeyTooShort) {
+		t.Fatalf("expect ErrKeyTooShort, got %v", err)
+	}
+	if _, err := ParseJWK([]byte(`{"kty":"oct","k":"AAAA"}`)); !errors.Is(err, ErrKeyTooShort) {
+		t.Fatalf("expect ErrKeyTooShort, got %v", err)
+	}
+}
+
+func TestJWKSet(t *testing.T) {
+	rsaKey := loadTestKey(t)
+	octKey, _ := NewOctJWK(bytes.Repeat([]byte{1}, 32))
+
+	set := &JWKSet{Keys: []*JWK{rsaKey, octKey}}
+	if set.Key(octKey.Kid) != octKey || set.Key("missing") != nil {
+		t.Fatalf("bad")
+	}
+
+	pub := set.Public()
+	if len(pub.Keys) != 1 || pub.Keys[0].IsPrivate() || pub.Keys[0].Kid != rsaKey.Kid {
+		t.Fatalf("bad: %+v", pub.Keys)
+	}
+}
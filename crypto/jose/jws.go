@@ -0,0 +1,216 @@
+package jose
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	rsakey "tools/crypto/rsa"
+)
+
+// Algorithm 是 JWA 算法标识
+type Algorithm string
+
+const (
+	PS512 Algorithm = "PS512"
+	RS256 Algorithm = "RS256"
+	HS256 Algorithm = "HS256"
+
+	RSAOAEP256 Algorithm = "RSA-OAEP-256"
+	A256GCM    Algorithm = "A256GCM"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("jose: unsupported algorithm")
+	ErrAlgorithmMismatch    = errors.New("jose: algorithm does not match the expected one")
+	ErrMalformedToken       = errors.New("jose: malformed token")
+	ErrInvalidSignature     = errors.New("jose: invalid signature")
+	ErrUnsupportedCritical  = errors.New("jose: unsupported critical header")
+)
+
+// Header 是 JOSE 头部中本包使用的字段
+type Header struct {
+	Alg  Algorithm `json:"alg"`
+	Enc  Algorithm `json:"enc,omitempty"`
+	Zip  string    `json:"zip,omitempty"`
+	Kid  string    `json:"kid,omitempty"`
+	Typ  string    `json:"typ,omitempty"`
+	Cty  string    `json:"cty,omitempty"`
+	Crit []string  `json:"crit,omitempty"`
+}
+
+// Sign 生成紧凑格式的 JWS，key 的 kid 会写入头部
+func Sign(payload []byte, alg Algorithm, key *JWK) (string, error) {
+	if err := checkKey(alg, key); err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(&Header{Alg: alg, Kid: key.Kid})
+	if err != nil {
+		return "", err
+	}
+
+	input := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+
+	sig, err := sign([]byte(input), alg, key)
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+// Verify 校验紧凑格式的 JWS 并返回 payload。
+// 头部的 alg 必须等于调用方期望的 alg，防止算法混淆（例如用 RSA 公钥做 HMAC 密钥）
+func Verify(token string, alg Algorithm, key *JWK) ([]byte, error) {
+	if err := checkKey(alg, key); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	header, err := parseHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if header.Alg != alg {
+		return nil, fmt.Errorf("%w: expect %s, got %q", ErrAlgorithmMismatch, alg, header.Alg)
+	}
+
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := verify([]byte(parts[0]+"."+parts[1]), sig, alg, key); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// ParseHeader 解析 JWS 或 JWE 的头部，不做任何校验，
+// 只用于在验签前根据 kid 选择密钥
+func ParseHeader(token string) (*Header, error) {
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return nil, ErrMalformedToken
+	}
+
+	return parseHeader(token[:i])
+}
+
+func parseHeader(s string) (*Header, error) {
+	raw, err := b64.DecodeString(s)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	var h Header
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	// 本包不理解任何扩展头部，带 crit 的 token 必须拒绝
+	if len(h.Crit) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedCritical, h.Crit)
+	}
+
+	return &h, nil
+}
+
+// checkKey 校验算法与密钥类型匹配，JWK 声明了 alg 时也必须一致
+func checkKey(alg Algorithm, key *JWK) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if key.Alg != "" && Algorithm(key.Alg) != alg {
+		return fmt.Errorf("%w: key is for %s", ErrAlgorithmMismatch, key.Alg)
+	}
+
+	switch alg {
+	case PS512, RS256, RSAOAEP256:
+		if key.Kty != KeyTypeRSA {
+			return fmt.Errorf("%w: %s requires an RSA key", ErrAlgorithmMismatch, alg)
+		}
+	case HS256:
+		if key.Kty != KeyTypeOct {
+			return fmt.Errorf("%w: %s requires an oct key", ErrAlgorithmMismatch, alg)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+
+	return nil
+}
+
+func signOptions(alg Algorithm) *rsakey.SignOptions {
+	if alg == RS256 {
+		return &rsakey.SignOptions{Hash: crypto.SHA256, Scheme: rsakey.SchemePKCS1v15}
+	}
+
+	// RFC 7518 3.5：PS512 的盐长度与哈希长度相同
+	return &rsakey.SignOptions{Hash: crypto.SHA512, SaltLength: rsa.PSSSaltLengthEqualsHash}
+}
+
+func sign(input []byte, alg Algorithm, key *JWK) ([]byte, error) {
+	if alg == HS256 {
+		return hs256(input, key)
+	}
+
+	k, err := key.RSAKey()
+	if err != nil {
+		return nil, err
+	}
+	if k.PrivateKey() == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	return k.SignBytes(input, signOptions(alg))
+}
+
+func verify(input, sig []byte, alg Algorithm, key *JWK) error {
+	if alg == HS256 {
+		mac, err := hs256(input, key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(mac, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	k, err := key.RSAKey()
+	if err != nil {
+		return err
+	}
+	if err := k.VerifyBytes(input, sig, signOptions(alg)); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func hs256(input []byte, key *JWK) ([]byte, error) {
+	secret, err := key.SymmetricKey()
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
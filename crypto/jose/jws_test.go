@@ -0,0 +1,139 @@
+package jose
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerify_RFC7515(t *testing.T) {
+	// RFC 7515 Appendix A.1
+	key := &JWK{Kty: KeyTypeOct, K: "AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	payload, err := Verify(token, HS256, key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	exp := "{\"iss\":\"joe\",\r\n \"exp\":1300819380,\r\n \"http://example.com/is_root\":true}"
+	if string(payload) != exp {
+		t.Fatalf("bad: %q", payload)
+	}
+
+	if _, err := Verify(strings.Replace(token, ".dBjf", ".dBjg", 1), HS256, key); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerify_openssl(t *testing.T) {
+	// openssl dgst -sha256 -sign 与 testdata/rsa.json 相同的私钥
+	token := "eyJhbGciOiJSUzI1NiJ9.eyJpc3MiOiJ0b29scyJ9" +
+		".GF0lQfxaDFrpZ36Dy8UYX3SgVeOxImOrJJ98evnz2GXri2dDcEk-Mqv_s2V0X2euqCRpBoqRq_dtA_Caq7ttJaO18abwKCov4t57_oa_ZyUuLeDKgyWp95SQ2eKs_VkIpl3cS43A200b5AIvSaNrfahYMnVVCGcOC37CsaV6I77X-lEtkom1rshGxOwgKj49n43dLvRhFqiAhYATjpOkGEfJXFGKDrkn4SYbG4QqPwrXqhov9Y_7p33QcWKQCt1Ywf0EPEHwNG13wO7rQTWL3Q3FBtsMb4sYW-2-eU9B9mEGX2LMoegU-19XXrfPsx0O-vSKGQbmtUhxgLAPxytGSw"
+
+	pub := loadTestKey(t).Public()
+	payload, err := Verify(token, RS256, pub)
+	if err != nil || string(payload) != `{"iss":"tools"}` {
+		t.Fatalf("bad: %s %v", payload, err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	rsaKey := loadTestKey(t)
+	octKey, _ := NewOctJWK([]byte("0123456789abcdef0123456789abcdef"))
+	payload := []byte(`{"sub":"websocket"}`)
+
+	for _, tc := range []struct {
+		alg         Algorithm
+		sign, check *JWK
+	}{
+		{PS512, rsaKey, rsaKey.Public()},
+		{RS256, rsaKey, rsaKey.Public()},
+		{HS256, octKey, octKey},
+	} {
+		token, err := Sign(payload, tc.alg, tc.sign)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.alg, err)
+		}
+
+		header, err := ParseHeader(token)
+		if err != nil || header.Alg != tc.alg || header.Kid != tc.sign.Kid {
+			t.Fatalf("%s: bad header %+v %v", tc.alg, header, err)
+		}
+
+		got, err := Verify(token, tc.alg, tc.check)
+		if err != nil || string(got) != string(payload) {
+			t.Fatalf("%s: bad: %s %v", tc.alg, got, err)
+		}
+
+		parts := strings.Split(token, ".")
+		forged := parts[0] + "." + b64.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+		if _, err := Verify(forged, tc.alg, tc.check); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("%s: expect ErrInvalidSignature, got %v", tc.alg, err)
+		}
+	}
+
+	if _, err := Sign(payload, PS512, rsaKey.Public()); !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("expect ErrNoPrivateKey, got %v", err)
+	}
+}
+
+func TestVerify_algorithmConfusion(t *testing.T) {
+	rsaKey := loadTestKey(t)
+	pub := rsaKey.Public()
+
+	// 攻击者把 RSA 公钥当作 HMAC 密钥签名，并把 alg 改成 HS256
+	data, _ := pub.Marshal()
+	hmacKey := &JWK{Kty: KeyTypeOct, K: b64.EncodeToString(data)}
+	forged, err := Sign([]byte("evil"), HS256, hmacKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := Verify(forged, RS256, pub); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+	if _, err := Verify(forged, HS256, pub); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+
+	// alg none
+	none := b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte("evil")) + "."
+	if _, err := Verify(none, PS512, pub); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+	if _, err := Verify(none, "none", pub); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("expect ErrUnsupportedAlgorithm, got %v", err)
+	}
+
+	// 头部 alg 与期望不一致
+	token, _ := Sign([]byte("hello"), RS256, rsaKey)
+	if _, err := Verify(token, PS512, pub); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+
+	// JWK 限定了 alg
+	restricted := *pub
+	restricted.Alg = string(PS512)
+	if _, err := Verify(token, RS256, &restricted); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expect ErrAlgorithmMismatch, got %v", err)
+	}
+}
+
+func TestVerify_malformed(t *testing.T) {
+	key, _ := NewOctJWK([]byte("0123456789abcdef0123456789abcdef"))
+
+	crit := b64.EncodeToString([]byte(`{"alg":"HS256","crit":["exp"],"exp":1}`)) + "." + b64.EncodeToString([]byte("x"))
+	mac, _ := hs256([]byte(crit), key)
+	if _, err := Verify(crit+"."+b64.EncodeToString(mac), HS256, key); !errors.Is(err, ErrUnsupportedCritical) {
+		t.Fatalf("expect ErrUnsupportedCritical, got %v", err)
+	}
+
+	hs := b64.EncodeToString([]byte(`{"alg":"HS256"}`))
+	for _, token := range []string{"", "a.b", "a.b.c.d", "!!.e30.e30", hs + ".!!.e30", hs + ".e30.!!"} {
+		if _, err := Verify(token, HS256, key); !errors.Is(err, ErrMalformedToken) {
+			t.Fatalf("%q: expect ErrMalformedToken, got %v", token, err)
+		}
+	}
+}
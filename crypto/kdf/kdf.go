@@ -0,0 +1,233 @@
+// Package kdf 从口令或高熵密钥材料派生 32 字节密钥，参数可序列化后与密文一起保存。
+// 解析参数时限制计算成本，不可信的参数块不会耗尽内存
+package kdf
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF 标识密钥派生算法，写入参数块的第二个字节
+type KDF byte
+
+const (
+	Argon2id KDF = 0x01
+	Scrypt   KDF = 0x02
+	// HKDF 没有计算成本，只适用于高熵的输入密钥材料，不要用于口令
+	HKDF KDF = 0x03
+)
+
+const (
+	paramsVersion = 0x01
+
+	// KeySize 为派生密钥的长度
+	KeySize = 32
+
+	defaultSaltSize = 16
+	minSaltSize     = 8
+
+	// 解析参数块时的上限，防止恶意参数耗尽内存或 CPU。
+	// scrypt 按 128·N·r·p 字节限制总成本，单独限制 N、r、p 不够
+	maxArgon2Time    = 64
+	maxArgon2Memory  = 1024 * 1024 // KiB，即 1 GiB
+	maxScryptLogN    = 24
+	maxScryptRP      = 1 << 20
+	maxScryptCost    = 1 << 30 // 字节
+	maxHKDFInfoBytes = 1024
+)
+
+var (
+	ErrUnknownKDF         = errors.New("unknown key derivation function")
+	ErrInvalidParams      = errors.New("invalid key derivation parameters")
+	ErrUnsupportedVersion = errors.New("unsupported key derivation parameters version")
+)
+
+// Params 描述一次密钥派生所需的全部参数，
+// 可通过 Marshal 序列化后与密文一起保存
+type Params struct {
+	KDF  KDF
+	Salt []byte
+
+	// Argon2id
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+
+	// scrypt
+	N int
+	R int
+	P int
+
+	// HKDF (SHA-256)
+	Info []byte
+}
+
+// NewParams 生成随机 salt 并填充推荐的成本参数
+func NewParams(kdf KDF) (*Params, error) {
+	p := &Params{
+		KDF:  kdf,
+		Salt: make([]byte, defaultSaltSize),
+	}
+
+	switch kdf {
+	case Argon2id:
+		// RFC 9106 推荐的第二组参数
+		p.Time, p.Memory, p.Threads = 3, 64*1024, 4
+	case Scrypt:
+		p.N, p.R, p.P = 1<<15, 8, 1
+	case HKDF:
+	default:
+		return nil, ErrUnknownKDF
+	}
+
+	if _, err := io.ReadFull(rand.Reader, p.Salt); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Marshal 将参数编码为自描述的二进制块：
+// version || kdf || saltLen || salt || kdf 相关参数
+func (p *Params) Marshal() ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	out := []byte{paramsVersion, byte(p.KDF), byte(len(p.Salt))}
+	out = append(out, p.Salt...)
+
+	switch p.KDF {
+	case Argon2id:
+		out = binary.BigEndian.AppendUint32(out, p.Time)
+		out = binary.BigEndian.AppendUint32(out, p.Memory)
+		out = append(out, p.Threads)
+	case Scrypt:
+		out = append(out, byte(bits.TrailingZeros(uint(p.N))))
+		out = binary.BigEndian.AppendUint32(out, uint32(p.R))
+		out = binary.BigEndian.AppendUint32(out, uint32(p.P))
+	case HKDF:
+		out = binary.BigEndian.AppendUint16(out, uint16(len(p.Info)))
+		out = append(out, p.Info...)
+	}
+
+	return out, nil
+}
+
+// ParseParams 解析 Marshal 产生的参数块
+func ParseParams(b []byte) (*Params, error) {
+	if len(b) < 3 {
+		return nil, ErrInvalidParams
+	}
+	if b[0] != paramsVersion {
+		return nil, ErrUnsupportedVersion
+	}
+
+	p := &Params{KDF: KDF(b[1])}
+	saltLen := int(b[2])
+	b = b[3:]
+	if len(b) < saltLen {
+		return nil, ErrInvalidParams
+	}
+	p.Salt = append([]byte{}, b[:saltLen]...)
+	b = b[saltLen:]
+
+	switch p.KDF {
+	case Argon2id:
+		if len(b) != 9 {
+			return nil, ErrInvalidParams
+		}
+		p.Time = binary.BigEndian.Uint32(b)
+		p.Memory = binary.BigEndian.Uint32(b[4:])
+		p.Threads = b[8]
+	case Scrypt:
+		if len(b) != 9 || b[0] > maxScryptLogN {
+			return nil, ErrInvalidParams
+		}
+		p.N = 1 << b[0]
+		p.R = int(binary.BigEndian.Uint32(b[1:]))
+		p.P = int(binary.BigEndian.Uint32(b[5:]))
+	case HKDF:
+		if len(b) < 2 || len(b[2:]) != int(binary.BigEndian.Uint16(b)) {
+			return nil, ErrInvalidParams
+		}
+		p.Info = append([]byte{}, b[2:]...)
+	default:
+		return nil, ErrUnknownKDF
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *Params) validate() error {
+	if len(p.Salt) > 255 {
+		return fmt.Errorf("%w: salt longer than 255 bytes", ErrInvalidParams)
+	}
+
+	switch p.KDF {
+	case Argon2id:
+		if len(p.Salt) < minSaltSize {
+			return fmt.Errorf("%w: salt must be at least %d bytes", ErrInvalidParams, minSaltSize)
+		}
+		if p.Time == 0 || p.Time > maxArgon2Time {
+			return fmt.Errorf("%w: argon2 time %d", ErrInvalidParams, p.Time)
+		}
+		if p.Threads == 0 || p.Memory < 8*uint32(p.Threads) || p.Memory > maxArgon2Memory {
+			return fmt.Errorf("%w: argon2 memory %d KiB threads %d", ErrInvalidParams, p.Memory, p.Threads)
+		}
+	case Scrypt:
+		if len(p.Salt) < minSaltSize {
+			return fmt.Errorf("%w: salt must be at least %d bytes", ErrInvalidParams, minSaltSize)
+		}
+		if p.N < 2 || p.N&(p.N-1) != 0 || p.N > 1<<maxScryptLogN {
+			return fmt.Errorf("%w: scrypt N must be a power of two", ErrInvalidParams)
+		}
+		if p.R <= 0 || p.P <= 0 || p.R > maxScryptRP || p.P > maxScryptRP {
+			return fmt.Errorf("%w: scrypt r %d p %d", ErrInvalidParams, p.R, p.P)
+		}
+		if uint64(p.R)*uint64(p.P) > maxScryptCost/128/uint64(p.N) {
+			return fmt.Errorf("%w: scrypt N %d r %d p %d exceeds %d bytes", ErrInvalidParams, p.N, p.R, p.P, maxScryptCost)
+		}
+	case HKDF:
+		if len(p.Info) > maxHKDFInfoBytes {
+			return fmt.Errorf("%w: hkdf info too long", ErrInvalidParams)
+		}
+	default:
+		return ErrUnknownKDF
+	}
+
+	return nil
+}
+
+// DeriveKey 按参数从 secret 派生 32 字节密钥
+func DeriveKey(secret []byte, p *Params) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	switch p.KDF {
+	case Argon2id:
+		return argon2.IDKey(secret, p.Salt, p.Time, p.Memory, p.Threads, KeySize), nil
+	case Scrypt:
+		return scrypt.Key(secret, p.Salt, p.N, p.R, p.P, KeySize)
+	default:
+		key := make([]byte, KeySize)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, secret, p.Salt, p.Info), key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+}
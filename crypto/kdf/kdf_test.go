@@ -0,0 +1,108 @@
+package kdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	for _, kdf := range []KDF{Argon2id, Scrypt, HKDF} {
+		params, err := NewParams(kdf)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Keep the tests fast
+		params.Memory, params.N = 1024, 1<<10
+
+		blob, err := params.Marshal()
+		if err != nil {
+			t.Fatalf("kdf %d: %v", kdf, err)
+		}
+
+		parsed, err := ParseParams(blob)
+		if err != nil {
+			t.Fatalf("kdf %d: %v", kdf, err)
+		}
+
+		k1, err := DeriveKey([]byte("correct horse battery staple"), params)
+		if err != nil {
+			t.Fatalf("kdf %d: %v", kdf, err)
+		}
+		k2, err := DeriveKey([]byte("correct horse battery staple"), parsed)
+		if err != nil {
+			t.Fatalf("kdf %d: %v", kdf, err)
+		}
+		if len(k1) != 32 || !bytes.Equal(k1, k2) {
+			t.Fatalf("kdf %d: bad: %x %x", kdf, k1, k2)
+		}
+
+		k3, _ := DeriveKey([]byte("Tr0ub4dor&3"), parsed)
+		if bytes.Equal(k1, k3) {
+			t.Fatalf("kdf %d: different passphrases derived the same key", kdf)
+		}
+	}
+}
+
+func TestDeriveKey_HKDF(t *testing.T) {
+	// RFC 5869 test case 1, first 32 bytes of OKM
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+
+	key, err := DeriveKey(ikm, &Params{KDF: HKDF, Salt: salt, Info: info})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	exp := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf"
+	if hex.EncodeToString(key) != exp {
+		t.Fatalf("bad: %x", key)
+	}
+}
+
+func TestParseParams_invalid(t *testing.T) {
+	params, _ := NewParams(Scrypt)
+	blob, _ := params.Marshal()
+
+	if _, err := ParseParams(blob[:len(blob)-1]); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expect ErrInvalidParams, got %v", err)
+	}
+
+	huge := append([]byte{}, blob...)
+	huge[3+len(params.Salt)] = 40
+	if _, err := ParseParams(huge); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expect ErrInvalidParams, got %v", err)
+	}
+
+	// N = 2^24, r = 2^20 passes the per-field limits but needs 2^51 bytes
+	costly := append([]byte{}, blob...)
+	costly[3+len(params.Salt)] = 24
+	binary.BigEndian.PutUint32(costly[4+len(params.Salt):], 1<<20)
+	if _, err := ParseParams(costly); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expect ErrInvalidParams, got %v", err)
+	}
+
+	greedy := &Params{KDF: Argon2id, Salt: params.Salt, Time: 1, Memory: 4 * 1024 * 1024, Threads: 1}
+	if _, err := greedy.Marshal(); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expect ErrInvalidParams, got %v", err)
+	}
+
+	unknown := append([]byte{}, blob...)
+	unknown[1] = 0x7f
+	if _, err := ParseParams(unknown); !errors.Is(err, ErrUnknownKDF) {
+		t.Fatalf("expect ErrUnknownKDF, got %v", err)
+	}
+
+	if _, err := NewParams(KDF(0)); !errors.Is(err, ErrUnknownKDF) {
+		t.Fatalf("expect ErrUnknownKDF, got %v", err)
+	}
+
+	weak := &Params{KDF: Argon2id, Salt: []byte("short"), Time: 1, Memory: 1024, Threads: 1}
+	if _, err := DeriveKey([]byte("pw"), weak); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("expect ErrInvalidParams, got %v", err)
+	}
+}
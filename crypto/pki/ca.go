@@ -0,0 +1,99 @@
+package pki
+
+import (
+	"crypto/x509"
+
+	rsakey "tools/crypto/rsa"
+)
+
+// CA 是一个最简单的本地证书颁发机构，只保存根证书和私钥，不维护吊销列表
+type CA struct {
+	Certificate *x509.Certificate
+
+	key *rsakey.RSAKey
+}
+
+// NewCA 使用 key 生成自签名的根证书，opts.IsCA 总是被置为 true
+func NewCA(key *rsakey.RSAKey, opts *CertOptions) (*CA, error) {
+	o := CertOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.IsCA = true
+
+	cert, err := SelfSign(key, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{Certificate: cert, key: key}, nil
+}
+
+// LoadCA 使用已有的 CA 证书和对应私钥构造 CA
+func LoadCA(cert *x509.Certificate, key *rsakey.RSAKey) (*CA, error) {
+	if !cert.IsCA || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, ErrNotCA
+	}
+	if key.PrivateKey() == nil {
+		return nil, rsakey.ErrNoPrivateKey
+	}
+	if !key.PublicKey().Equal(cert.PublicKey) {
+		return nil, ErrKeyMismatch
+	}
+
+	return &CA{Certificate: cert, key: key}, nil
+}
+
+// Issue 为 key 的公钥签发证书，key 可以只包含公钥
+func (ca *CA) Issue(key *rsakey.RSAKey, opts *CertOptions) (*x509.Certificate, error) {
+	tmpl, err := ca.template(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return createCertificate(tmpl, ca.Certificate, key.PublicKey(), ca.key)
+}
+
+// SignCSR 根据 CSR 签发证书。主题和 SAN 取自 CSR，
+// opts 中的主题和 SAN 被忽略，只使用有效期、用途和 CA 相关字段
+func (ca *CA) SignCSR(csr *x509.CertificateRequest, opts *CertOptions) (*x509.Certificate, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := ca.template(opts)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Subject = csr.Subject
+	tmpl.DNSNames = csr.DNSNames
+	tmpl.IPAddresses = csr.IPAddresses
+	tmpl.EmailAddresses = csr.EmailAddresses
+	tmpl.URIs = csr.URIs
+
+	return createCertificate(tmpl, ca.Certificate, csr.PublicKey, ca.key)
+}
+
+// CertPool 返回只包含该 CA 根证书的证书池，用作 TLS 的 RootCAs 或 ClientCAs
+func (ca *CA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+	return pool
+}
+
+// template 生成由该 CA 签发的证书模板，有效期不超过 CA 证书
+func (ca *CA) template(opts *CertOptions) (*x509.Certificate, error) {
+	tmpl, err := template(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if tmpl.NotAfter.After(ca.Certificate.NotAfter) {
+		tmpl.NotAfter = ca.Certificate.NotAfter
+	}
+	if !tmpl.NotAfter.After(tmpl.NotBefore) {
+		return nil, ErrInvalidValidity
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,75 @@
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCA(t *testing.T) {
+	caKey := genKey(t)
+	ca, err := NewCA(caKey, &CertOptions{Subject: pkix.Name{CommonName: "tools test CA"}, ValidFor: 48 * time.Hour})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !ca.Certificate.IsCA || !ca.Certificate.MaxPathLenZero || ca.Certificate.KeyUsage&x509.KeyUsageCertSign == 0 {
+		t.Fatalf("bad: %+v", ca.Certificate)
+	}
+
+	// 通过 CSR 签发，主题和 SAN 以 CSR 为准
+	leafKey := genKey(t)
+	der, _ := CreateCSR(leafKey, &CertOptions{Subject: pkix.Name{CommonName: "ws"}, DNSNames: []string{"ws.internal"}})
+	csr, _ := ParseCSR(der)
+
+	cert, err := ca.SignCSR(csr, &CertOptions{
+		DNSNames:    []string{"evil.example"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if cert.Subject.CommonName != "ws" || len(cert.DNSNames) != 1 || cert.DNSNames[0] != "ws.internal" {
+		t.Fatalf("bad: %v %v", cert.Subject, cert.DNSNames)
+	}
+
+	// 有效期不超过 CA
+	if cert.NotAfter.After(ca.Certificate.NotAfter) {
+		t.Fatalf("bad: %v > %v", cert.NotAfter, ca.Certificate.NotAfter)
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:   "ws.internal",
+		Roots:     ca.CertPool(),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{Roots: ca.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	if err == nil {
+		t.Fatalf("expect key usage error")
+	}
+
+	// 不能用叶子证书的私钥冒充 CA
+	if _, err := LoadCA(cert, leafKey); !errors.Is(err, ErrNotCA) {
+		t.Fatalf("expect ErrNotCA, got %v", err)
+	}
+	if _, err := LoadCA(ca.Certificate, leafKey); !errors.Is(err, ErrKeyMismatch) {
+		t.Fatalf("expect ErrKeyMismatch, got %v", err)
+	}
+
+	loaded, err := LoadCA(ca.Certificate, caKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	client, err := loaded.Issue(leafKey, &CertOptions{Subject: pkix.Name{CommonName: "client"}})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := client.CheckSignatureFrom(ca.Certificate); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
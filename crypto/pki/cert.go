@@ -0,0 +1,216 @@
+// Package pki 使用 crypto/rsa 的 RSAKey 生成 CSR、自签名证书和 CA 签发的证书，
+// 并提供 TLS / mTLS 配置，替代内部 TLS 对 openssl 命令行的依赖
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+
+	rsakey "tools/crypto/rsa"
+)
+
+const (
+	DefaultLeafValidity = 365 * 24 * time.Hour
+	DefaultCAValidity   = 10 * 365 * 24 * time.Hour
+
+	// clockSkew 让 NotBefore 略早于当前时间，容忍对端时钟偏差
+	clockSkew = 5 * time.Minute
+
+	pemCertificate        = "CERTIFICATE"
+	pemCertificateRequest = "CERTIFICATE REQUEST"
+)
+
+var (
+	ErrInvalidValidity = errors.New("pki: invalid certificate validity")
+	ErrNotCA           = errors.New("pki: certificate is not a CA")
+	ErrKeyMismatch     = errors.New("pki: private key does not match the certificate")
+	ErrInvalidPEM      = errors.New("pki: invalid PEM block")
+)
+
+// CertOptions 描述证书或 CSR 的内容，零值字段使用默认值
+type CertOptions struct {
+	Subject pkix.Name
+
+	// SAN
+	DNSNames       []string
+	IPAddresses    []net.IP
+	EmailAddresses []string
+	URIs           []*url.URL
+
+	// NotBefore 为零时取当前时间减去 5 分钟；
+	// ValidFor 为零时叶子证书一年，CA 十年
+	NotBefore time.Time
+	ValidFor  time.Duration
+
+	// KeyUsage 为零时叶子证书为 DigitalSignature | KeyEncipherment，
+	// CA 为 CertSign | CRLSign | DigitalSignature；
+	// ExtKeyUsage 为空时叶子证书同时允许 ServerAuth 和 ClientAuth
+	KeyUsage    x509.KeyUsage
+	ExtKeyUsage []x509.ExtKeyUsage
+
+	// IsCA 生成 CA 证书，MaxPathLen 为 0 时不能再签发下级 CA
+	IsCA       bool
+	MaxPathLen int
+}
+
+// CreateCSR 使用 key 生成 DER 编码的 CSR，只使用 opts 中的主题和 SAN
+func CreateCSR(key *rsakey.RSAKey, opts *CertOptions) ([]byte, error) {
+	if key.PrivateKey() == nil {
+		return nil, rsakey.ErrNoPrivateKey
+	}
+	if opts == nil {
+		opts = &CertOptions{}
+	}
+
+	tmpl := &x509.CertificateRequest{
+		Subject:        opts.Subject,
+		DNSNames:       opts.DNSNames,
+		IPAddresses:    opts.IPAddresses,
+		EmailAddresses: opts.EmailAddresses,
+		URIs:           opts.URIs,
+	}
+
+	return x509.CreateCertificateRequest(rand.Reader, tmpl, key.PrivateKey())
+}
+
+// ParseCSR 解析 DER 或 PEM 编码的 CSR 并校验其签名
+func ParseCSR(data []byte) (*x509.CertificateRequest, error) {
+	der, err := fromPEM(data, pemCertificateRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("pki: bad csr signature: %w", err)
+	}
+
+	return csr, nil
+}
+
+// SelfSign 生成自签名证书
+func SelfSign(key *rsakey.RSAKey, opts *CertOptions) (*x509.Certificate, error) {
+	if key.PrivateKey() == nil {
+		return nil, rsakey.ErrNoPrivateKey
+	}
+
+	tmpl, err := template(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return createCertificate(tmpl, tmpl, key.PublicKey(), key)
+}
+
+// ParseCertificate 解析 DER 或 PEM 编码的证书
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	der, err := fromPEM(data, pemCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// EncodeCertificatePEM 将证书编码为 PEM
+func EncodeCertificatePEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemCertificate, Bytes: cert.Raw})
+}
+
+// EncodeCSRPEM 将 DER 编码的 CSR 转换为 PEM
+func EncodeCSRPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemCertificateRequest, Bytes: der})
+}
+
+func template(opts *CertOptions) (*x509.Certificate, error) {
+	if opts == nil {
+		opts = &CertOptions{}
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := opts.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now().Add(-clockSkew)
+	}
+
+	validFor := opts.ValidFor
+	if validFor == 0 {
+		validFor = DefaultLeafValidity
+		if opts.IsCA {
+			validFor = DefaultCAValidity
+		}
+	}
+	if validFor < 0 {
+		return nil, ErrInvalidValidity
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        opts.Subject,
+		DNSNames:       opts.DNSNames,
+		IPAddresses:    opts.IPAddresses,
+		EmailAddresses: opts.EmailAddresses,
+		URIs:           opts.URIs,
+		NotBefore:      notBefore,
+		NotAfter:       notBefore.Add(validFor),
+		KeyUsage:       opts.KeyUsage,
+		ExtKeyUsage:    opts.ExtKeyUsage,
+
+		BasicConstraintsValid: true,
+		IsCA:                  opts.IsCA,
+		MaxPathLen:            opts.MaxPathLen,
+		MaxPathLenZero:        opts.IsCA && opts.MaxPathLen == 0,
+	}
+
+	if opts.IsCA {
+		if tmpl.KeyUsage == 0 {
+			tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+		}
+	} else {
+		if tmpl.KeyUsage == 0 {
+			tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		}
+		if len(tmpl.ExtKeyUsage) == 0 {
+			tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		}
+	}
+
+	return tmpl, nil
+}
+
+func createCertificate(tmpl, parent *x509.Certificate, pub any, signer *rsakey.RSAKey) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, signer.PrivateKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// fromPEM 接受 PEM 或 DER，PEM 时类型必须为 typ
+func fromPEM(data []byte, typ string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return data, nil
+	}
+	if block.Type != typ {
+		return nil, fmt.Errorf("%w: expect %s, got %s", ErrInvalidPEM, typ, block.Type)
+	}
+
+	return block.Bytes, nil
+}
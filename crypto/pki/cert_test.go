@@ -0,0 +1,102 @@
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	rsakey "tools/crypto/rsa"
+)
+
+func genKey(t testing.TB) *rsakey.RSAKey {
+	t.Helper()
+
+	key, err := rsakey.GenRSAKeyWithSize(2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return key
+}
+
+func TestCreateCSR(t *testing.T) {
+	key := genKey(t)
+
+	der, err := CreateCSR(key, &CertOptions{
+		Subject:     pkix.Name{CommonName: "ws.internal", Organization: []string{"tools"}},
+		DNSNames:    []string{"ws.internal", "localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for _, data := range [][]byte{der, EncodeCSRPEM(der)} {
+		csr, err := ParseCSR(data)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if csr.Subject.CommonName != "ws.internal" || len(csr.DNSNames) != 2 || !csr.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")) {
+			t.Fatalf("bad: %+v", csr)
+		}
+		if !key.PublicKey().Equal(csr.PublicKey) {
+			t.Fatalf("bad public key")
+		}
+	}
+
+	if _, err := CreateCSR(rsakey.NewRSAPublicKey(key.PublicKey()), nil); !errors.Is(err, rsakey.ErrNoPrivateKey) {
+		t.Fatalf("expect ErrNoPrivateKey, got %v", err)
+	}
+
+	tampered := append([]byte{}, der...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := ParseCSR(tampered); err == nil {
+		t.Fatalf("expect signature error")
+	}
+}
+
+func TestSelfSign(t *testing.T) {
+	key := genKey(t)
+	notBefore := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	cert, err := SelfSign(key, &CertOptions{
+		Subject:   pkix.Name{CommonName: "localhost"},
+		DNSNames:  []string{"localhost"},
+		NotBefore: notBefore,
+		ValidFor:  24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !cert.NotBefore.Equal(notBefore) || !cert.NotAfter.Equal(notBefore.Add(24*time.Hour)) {
+		t.Fatalf("bad validity: %v %v", cert.NotBefore, cert.NotAfter)
+	}
+	if cert.IsCA || cert.KeyUsage != x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment || len(cert.ExtKeyUsage) != 2 {
+		t.Fatalf("bad usage: %v %v %v", cert.IsCA, cert.KeyUsage, cert.ExtKeyUsage)
+	}
+	if err := cert.VerifyHostname("localhost"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := cert.CheckSignatureFrom(cert); err == nil {
+		// 非 CA 证书不能作为签发者
+		t.Fatalf("expect error")
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	parsed, err := ParseCertificate(EncodeCertificatePEM(cert))
+	if err != nil || !parsed.Equal(cert) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	if _, err := ParseCertificate(EncodeCSRPEM([]byte("x"))); !errors.Is(err, ErrInvalidPEM) {
+		t.Fatalf("expect ErrInvalidPEM, got %v", err)
+	}
+	if _, err := SelfSign(key, &CertOptions{ValidFor: -time.Hour}); !errors.Is(err, ErrInvalidValidity) {
+		t.Fatalf("expect ErrInvalidValidity, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package pki
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	rsakey "tools/crypto/rsa"
+)
+
+// TLSCertificate 将证书、私钥和中间证书组合为 tls.Certificate
+func TLSCertificate(cert *x509.Certificate, key *rsakey.RSAKey, chain ...*x509.Certificate) (tls.Certificate, error) {
+	if key.PrivateKey() == nil {
+		return tls.Certificate{}, rsakey.ErrNoPrivateKey
+	}
+	if !key.PublicKey().Equal(cert.PublicKey) {
+		return tls.Certificate{}, ErrKeyMismatch
+	}
+
+	out := tls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  key.PrivateKey(),
+		Leaf:        cert,
+	}
+	for _, c := range chain {
+		out.Certificate = append(out.Certificate, c.Raw)
+	}
+
+	return out, nil
+}
+
+// ServerTLSConfig 生成服务端 TLS 配置，最低 TLS 1.2。
+// clientCAs 非空时开启 mTLS，要求客户端出示由其签发的证书
+func ServerTLSConfig(cert tls.Certificate, clientCAs *x509.CertPool) *tls.Config {
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAs != nil {
+		cfg.ClientCAs = clientCAs
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg
+}
+
+// ClientTLSConfig 生成客户端 TLS 配置，只信任 roots 中的证书。
+// cert 为 nil 时不出示客户端证书
+func ClientTLSConfig(roots *x509.CertPool, cert *tls.Certificate) *tls.Config {
+	cfg := &tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+
+	return cfg
+}
@@ -0,0 +1,78 @@
+package pki
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTLSConfig(t *testing.T) {
+	ca, err := NewCA(genKey(t), &CertOptions{Subject: pkix.Name{CommonName: "tools test CA"}})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	serverKey := genKey(t)
+	serverCert, err := ca.Issue(serverKey, &CertOptions{
+		Subject:     pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	serverTLS, err := TLSCertificate(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	clientKey := genKey(t)
+	clientCert, _ := ca.Issue(clientKey, &CertOptions{Subject: pkix.Name{CommonName: "frontend"}})
+	clientTLS, err := TLSCertificate(clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := TLSCertificate(clientCert, serverKey); !errors.Is(err, ErrKeyMismatch) {
+		t.Fatalf("expect ErrKeyMismatch, got %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = ServerTLSConfig(serverTLS, ca.CertPool())
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(cfg *tls.Config) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	body, err := get(ClientTLSConfig(ca.CertPool(), &clientTLS))
+	if err != nil || body != "frontend" {
+		t.Fatalf("bad: %s %v", body, err)
+	}
+
+	// mTLS 要求客户端证书
+	if _, err := get(ClientTLSConfig(ca.CertPool(), nil)); err == nil {
+		t.Fatalf("expect handshake error")
+	}
+
+	// 客户端不信任其他 CA 签发的服务端证书
+	other, _ := NewCA(genKey(t), nil)
+	if _, err := get(ClientTLSConfig(other.CertPool(), &clientTLS)); err == nil {
+		t.Fatalf("expect verification error")
+	}
+}
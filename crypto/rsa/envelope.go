@@ -0,0 +1,182 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	aes "tools/crypto/AES"
+)
+
+const (
+	envelopeVersion = 0x01
+	dataKeySize     = 32
+	keyIDSize       = sha256.Size
+
+	// maxRecipients 限制解析时的收件人数量
+	maxRecipients = 1024
+)
+
+// envelopeLabel 作为 OAEP 的 label，防止包装后的数据密钥被挪作他用
+var envelopeLabel = []byte("tools/crypto/rsa envelope v1")
+
+var (
+	ErrNoRecipients      = errors.New("envelope needs at least one recipient")
+	ErrNotRecipient      = errors.New("key is not a recipient of the envelope")
+	ErrEnvelopeCorrupted = errors.New("envelope corrupted")
+	ErrUnsupportedFormat = errors.New("unsupported envelope version")
+)
+
+// SealEnvelope 使用混合加密封装任意长度的 msg：
+// 随机生成 AES-256 数据密钥加密 msg，再用每个收件人的公钥以 RSA-OAEP-SHA256 包装数据密钥。
+//
+// 输出格式：
+//
+//	version(1) || count(2) || count × (keyID(32) || wrappedLen(2) || wrapped) || aes.Seal 输出
+//
+// keyID 为公钥 PKIX 编码的 SHA-256。AES 密文以头部和 ad 作为附加数据，
+// 收件人列表被篡改时解密失败
+func SealEnvelope(msg, ad []byte, recipients ...*RSAKey) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+	if len(recipients) > maxRecipients {
+		return nil, fmt.Errorf("too many recipients: %d", len(recipients))
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	header := []byte{envelopeVersion}
+	header = binary.BigEndian.AppendUint16(header, uint16(len(recipients)))
+
+	for i, r := range recipients {
+		if r == nil || r.pub == nil {
+			return nil, fmt.Errorf("recipient %d: %w", i, ErrNoPublicKey)
+		}
+
+		id, err := KeyID(r.pub)
+		if err != nil {
+			return nil, err
+		}
+
+		wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, r.pub, dataKey, envelopeLabel)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %d: %w", i, err)
+		}
+
+		header = append(header, id...)
+		header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+		header = append(header, wrapped...)
+	}
+
+	key, err := aes.NewAESKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := key.Seal(msg, envelopeAD(header, ad))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, sealed...), nil
+}
+
+// OpenEnvelope 使用私钥打开 SealEnvelope 的输出，ad 必须与封装时一致
+func (c *RSAKey) OpenEnvelope(blob, ad []byte) ([]byte, error) {
+	if c.priv == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	id, err := KeyID(c.pub)
+	if err != nil {
+		return nil, err
+	}
+
+	header, recipients, err := parseEnvelope(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, ok := recipients[string(id)]
+	if !ok {
+		return nil, ErrNotRecipient
+	}
+
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), nil, c.priv, wrapped, envelopeLabel)
+	if err != nil {
+		return nil, ErrEnvelopeCorrupted
+	}
+	defer clear(dataKey)
+
+	key, err := aes.NewAESKey(dataKey)
+	if err != nil {
+		return nil, ErrEnvelopeCorrupted
+	}
+
+	msg, err := key.Open(blob[len(header):], envelopeAD(header, ad))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEnvelopeCorrupted, err)
+	}
+
+	return msg, nil
+}
+
+// KeyID 返回公钥的标识：PKIX 编码的 SHA-256
+func KeyID(pub *rsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(der)
+	return sum[:], nil
+}
+
+// parseEnvelope 返回头部和 keyID -> 包装后数据密钥的映射
+func parseEnvelope(blob []byte) ([]byte, map[string][]byte, error) {
+	if len(blob) < 3 {
+		return nil, nil, ErrEnvelopeCorrupted
+	}
+	if blob[0] != envelopeVersion {
+		return nil, nil, ErrUnsupportedFormat
+	}
+
+	count := int(binary.BigEndian.Uint16(blob[1:]))
+	if count == 0 || count > maxRecipients {
+		return nil, nil, ErrEnvelopeCorrupted
+	}
+
+	recipients := make(map[string][]byte, count)
+	rest := blob[3:]
+	for i := 0; i < count; i++ {
+		if len(rest) < keyIDSize+2 {
+			return nil, nil, ErrEnvelopeCorrupted
+		}
+		id := rest[:keyIDSize]
+		n := int(binary.BigEndian.Uint16(rest[keyIDSize:]))
+		rest = rest[keyIDSize+2:]
+
+		if len(rest) < n {
+			return nil, nil, ErrEnvelopeCorrupted
+		}
+		recipients[string(id)] = rest[:n]
+		rest = rest[n:]
+	}
+
+	return blob[:len(blob)-len(rest)], recipients, nil
+}
+
+func envelopeAD(header, ad []byte) []byte {
+	return bytes.Join([][]byte{header, ad}, nil)
+}
@@ -0,0 +1,99 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func testKeys(t *testing.T, n int) []*RSAKey {
+	t.Helper()
+
+	keys := make([]*RSAKey, n)
+	for i := range keys {
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		keys[i] = NewRSAKey(priv)
+	}
+	return keys
+}
+
+func TestEnvelope(t *testing.T) {
+	keys := testKeys(t, 3)
+
+	// Far beyond what a single OAEP block can carry
+	msg := make([]byte, 1<<20)
+	rand.Read(msg)
+	ad := []byte("file.bin")
+
+	blob, err := SealEnvelope(msg, ad, keys[0], NewRSAPublicKey(keys[1].PublicKey()))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for _, k := range keys[:2] {
+		out, err := k.OpenEnvelope(blob, ad)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !bytes.Equal(out, msg) {
+			t.Fatalf("bad: plaintext differs")
+		}
+	}
+
+	if _, err := keys[2].OpenEnvelope(blob, ad); !errors.Is(err, ErrNotRecipient) {
+		t.Fatalf("expect ErrNotRecipient, got %v", err)
+	}
+	if _, err := keys[0].OpenEnvelope(blob, []byte("other.bin")); !errors.Is(err, ErrEnvelopeCorrupted) {
+		t.Fatalf("expect ErrEnvelopeCorrupted, got %v", err)
+	}
+	if _, err := NewRSAPublicKey(keys[0].PublicKey()).OpenEnvelope(blob, ad); !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("expect ErrNoPrivateKey, got %v", err)
+	}
+}
+
+func TestEnvelope_tamper(t *testing.T) {
+	keys := testKeys(t, 2)
+
+	blob, err := SealEnvelope([]byte("hello"), nil, keys[0], keys[1])
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	tampered := append([]byte{}, blob...)
+	tampered[len(tampered)-40] ^= 0x01
+	if _, err := keys[0].OpenEnvelope(tampered, nil); !errors.Is(err, ErrEnvelopeCorrupted) {
+		t.Fatalf("expect ErrEnvelopeCorrupted, got %v", err)
+	}
+
+	// Changing the other recipient's entry breaks the authenticated header
+	header, _, _ := parseEnvelope(blob)
+	tampered = append([]byte{}, blob...)
+	tampered[len(header)-1] ^= 0x01
+	if _, err := keys[0].OpenEnvelope(tampered, nil); !errors.Is(err, ErrEnvelopeCorrupted) {
+		t.Fatalf("expect ErrEnvelopeCorrupted, got %v", err)
+	}
+
+	for _, b := range [][]byte{nil, blob[:2], blob[:40], blob[:len(header)-1]} {
+		if _, err := keys[0].OpenEnvelope(b, nil); !errors.Is(err, ErrEnvelopeCorrupted) {
+			t.Fatalf("expect ErrEnvelopeCorrupted, got %v", err)
+		}
+	}
+
+	version := append([]byte{}, blob...)
+	version[0] = 0x7f
+	if _, err := keys[0].OpenEnvelope(version, nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expect ErrUnsupportedFormat, got %v", err)
+	}
+
+	if _, err := SealEnvelope([]byte("hello"), nil); !errors.Is(err, ErrNoRecipients) {
+		t.Fatalf("expect ErrNoRecipients, got %v", err)
+	}
+	if _, err := SealEnvelope([]byte("hello"), nil, keys[0], &RSAKey{}); !errors.Is(err, ErrNoPublicKey) {
+		t.Fatalf("expect ErrNoPublicKey, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrNoPrivateKey       = errors.New("rsa private key has not init")
+	ErrNoPublicKey        = errors.New("rsa public key has not init")
+	ErrNotRSAKey          = errors.New("not an rsa key")
+	ErrUnknownKeyFormat   = errors.New("unknown key format")
+	ErrPassphraseRequired = errors.New("key is encrypted, passphrase required")
+	ErrWrongPassphrase    = errors.New("wrong passphrase or corrupted key")
+)
+
+// NewRSAKey 使用已有的私钥构造 RSAKey
+func NewRSAKey(priv *rsa.PrivateKey) *RSAKey {
+	return &RSAKey{
+		priv: priv,
+		pub:  &priv.PublicKey,
+	}
+}
+
+// NewRSAPublicKey 构造只能加密和验签的 RSAKey
+func NewRSAPublicKey(pub *rsa.PublicKey) *RSAKey {
+	return &RSAKey{pub: pub}
+}
+
+func (c *RSAKey) PublicKey() *rsa.PublicKey {
+	return c.pub
+}
+
+// PrivateKey 返回私钥，只有公钥时返回 nil
+func (c *RSAKey) PrivateKey() *rsa.PrivateKey {
+	return c.priv
+}
+
+// LoadRSAPrivateKey 从文件加载私钥，见 ParseRSAPrivateKey
+func LoadRSAPrivateKey(path string, passphrase []byte) (*RSAKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRSAPrivateKey(data, passphrase)
+}
+
+// ParseRSAPrivateKey 解析 PKCS#1、PKCS#8（PEM 或 DER）和 OpenSSH 格式的私钥。
+// 支持口令加密的 PKCS#8（PBES2）、传统 PEM 加密和 OpenSSH 加密，
+// 未加密的私钥忽略 passphrase
+func ParseRSAPrivateKey(data, passphrase []byte) (*RSAKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return parsePrivateDER(data)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY", "PRIVATE KEY":
+		der := block.Bytes
+
+		// 传统 PEM 加密已不推荐，但 openssl -traditional 仍会输出这种格式
+		if x509.IsEncryptedPEMBlock(block) {
+			if len(passphrase) == 0 {
+				return nil, ErrPassphraseRequired
+			}
+
+			var err error
+			if der, err = x509.DecryptPEMBlock(block, passphrase); err != nil {
+				return nil, ErrWrongPassphrase
+			}
+		}
+
+		return parsePrivateDER(der)
+
+	case "ENCRYPTED PRIVATE KEY":
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+
+		der, err := decryptPKCS8(block.Bytes, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return parsePrivateDER(der)
+
+	case "OPENSSH PRIVATE KEY":
+		key, err := ssh.ParseRawPrivateKey(data)
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			if len(passphrase) == 0 {
+				return nil, ErrPassphraseRequired
+			}
+			if key, err = ssh.ParseRawPrivateKeyWithPassphrase(data, passphrase); err != nil {
+				return nil, ErrWrongPassphrase
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+		}
+
+		return privateKey(key)
+	}
+
+	return nil, fmt.Errorf("%w: pem type %q", ErrUnknownKeyFormat, block.Type)
+}
+
+// LoadRSAPublicKey 从文件加载公钥，见 ParseRSAPublicKey
+func LoadRSAPublicKey(path string) (*RSAKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRSAPublicKey(data)
+}
+
+// ParseRSAPublicKey 解析 PKIX、PKCS#1（PEM 或 DER）和 OpenSSH authorized_keys 格式的公钥，
+// 返回的 RSAKey 只能加密和验签
+func ParseRSAPublicKey(data []byte) (*RSAKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("ssh-rsa ")) {
+			key, _, _, _, err := ssh.ParseAuthorizedKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+			}
+
+			pub, ok := key.(ssh.CryptoPublicKey)
+			if !ok {
+				return nil, ErrNotRSAKey
+			}
+			return publicKey(pub.CryptoPublicKey())
+		}
+
+		return parsePublicDER(data)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY", "RSA PUBLIC KEY":
+		return parsePublicDER(block.Bytes)
+	}
+
+	return nil, fmt.Errorf("%w: pem type %q", ErrUnknownKeyFormat, block.Type)
+}
+
+func parsePrivateDER(der []byte) (*RSAKey, error) {
+	if priv, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return NewRSAKey(priv), nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, ErrUnknownKeyFormat
+	}
+	return privateKey(key)
+}
+
+func parsePublicDER(der []byte) (*RSAKey, error) {
+	if pub, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return NewRSAPublicKey(pub), nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, ErrUnknownKeyFormat
+	}
+	return publicKey(key)
+}
+
+func privateKey(key any) (*RSAKey, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return NewRSAKey(k), nil
+	default:
+		return nil, ErrNotRSAKey
+	}
+}
+
+func publicKey(key any) (*RSAKey, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return NewRSAPublicKey(k), nil
+	default:
+		return nil, ErrNotRSAKey
+	}
+}
@@ -0,0 +1,155 @@
+package rsa
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var passphrase = []byte("correct horse")
+
+func TestLoadRSAPrivateKey(t *testing.T) {
+	expected, err := LoadRSAPrivateKey("testdata/pkcs1.pem", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for file, pass := range map[string][]byte{
+		"pkcs8.pem":           nil,
+		"pkcs1_encrypted.pem": passphrase,
+		"pkcs8_pbkdf2.pem":    passphrase,
+		"pkcs8_scrypt.pem":    passphrase,
+		"id_rsa":              nil,
+		"id_rsa_encrypted":    passphrase,
+	} {
+		key, err := LoadRSAPrivateKey(filepath.Join("testdata", file), pass)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if !key.PrivateKey().Equal(expected.PrivateKey()) || !key.PublicKey().Equal(expected.PublicKey()) {
+			t.Fatalf("%s: loaded a different key", file)
+		}
+	}
+
+	// DER without PEM armor
+	data, _ := os.ReadFile("testdata/pkcs8.pem")
+	block, _ := pem.Decode(data)
+	if key, err := ParseRSAPrivateKey(block.Bytes, nil); err != nil || !key.PrivateKey().Equal(expected.PrivateKey()) {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestLoadRSAPrivateKey_passphrase(t *testing.T) {
+	for _, file := range []string{"pkcs1_encrypted.pem", "pkcs8_pbkdf2.pem", "pkcs8_scrypt.pem", "id_rsa_encrypted"} {
+		path := filepath.Join("testdata", file)
+
+		if _, err := LoadRSAPrivateKey(path, nil); !errors.Is(err, ErrPassphraseRequired) {
+			t.Fatalf("%s: expect ErrPassphraseRequired, got %v", file, err)
+		}
+		if _, err := LoadRSAPrivateKey(path, []byte("Tr0ub4dor&3")); !errors.Is(err, ErrWrongPassphrase) {
+			t.Fatalf("%s: expect ErrWrongPassphrase, got %v", file, err)
+		}
+	}
+}
+
+func TestParseRSAPrivateKey_scryptParams(t *testing.T) {
+	data, _ := os.ReadFile("testdata/pkcs8_scrypt.pem")
+	block, _ := pem.Decode(data)
+
+	var info encryptedPrivateKeyInfo
+	var params pbes2Params
+	var kdf scryptParams
+	if _, err := asn1.Unmarshal(block.Bytes, &info); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Every set passes the N limit alone, the first would need about 2^58 bytes
+	for _, c := range []struct{ n, r, p int }{
+		{1 << 22, 1<<29 - 1, 1},
+		{1 << 14, 8, 1 << 20},
+		{1 << 14, 0, 1},
+		{1 << 14, 8, -1},
+		{-1, 8, 1},
+	} {
+		kdf.CostParameter, kdf.BlockSize, kdf.ParallelizationParameter = c.n, c.r, c.p
+		kdfDER, _ := asn1.Marshal(kdf)
+		params.KeyDerivationFunc.Parameters = asn1.RawValue{FullBytes: kdfDER}
+		paramsDER, _ := asn1.Marshal(params)
+		info.Algorithm.Parameters = asn1.RawValue{FullBytes: paramsDER}
+		der, _ := asn1.Marshal(info)
+		crafted := pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der})
+
+		if _, err := ParseRSAPrivateKey(crafted, passphrase); !errors.Is(err, ErrUnknownKeyFormat) {
+			t.Fatalf("%+v: expect ErrUnknownKeyFormat, got %v", c, err)
+		}
+	}
+}
+
+func TestLoadRSAPublicKey(t *testing.T) {
+	expected, _ := LoadRSAPrivateKey("testdata/pkcs1.pem", nil)
+
+	for _, file := range []string{"public.pem", "public_pkcs1.pem", "id_rsa.pub"} {
+		key, err := LoadRSAPublicKey(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if !key.PublicKey().Equal(expected.PublicKey()) || key.PrivateKey() != nil {
+			t.Fatalf("%s: bad key", file)
+		}
+	}
+
+	// A public-only key verifies and encrypts but cannot sign or decrypt
+	pub, _ := LoadRSAPublicKey("testdata/public.pem")
+	msg := "public only"
+
+	sig, _ := expected.Sign(msg)
+	if !CheckSign(msg, sig, pub.PublicKey()) {
+		t.Fatalf("signature rejected")
+	}
+	if _, err := pub.Sign(msg); !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("expect ErrNoPrivateKey, got %v", err)
+	}
+	if out := expected.Decrypt(pub.Encrypt(msg)); out != msg {
+		t.Fatalf("bad: %s", out)
+	}
+	if out := pub.Decrypt(pub.Encrypt(msg)); out != "" {
+		t.Fatalf("bad: %s", out)
+	}
+}
+
+func TestParseRSAKey_invalid(t *testing.T) {
+	if _, err := ParseRSAPrivateKey([]byte("not a key"), nil); !errors.Is(err, ErrUnknownKeyFormat) {
+		t.Fatalf("expect ErrUnknownKeyFormat, got %v", err)
+	}
+	if _, err := ParseRSAPublicKey([]byte("not a key")); !errors.Is(err, ErrUnknownKeyFormat) {
+		t.Fatalf("expect ErrUnknownKeyFormat, got %v", err)
+	}
+
+	// A public key is not a private key
+	data, _ := os.ReadFile("testdata/public.pem")
+	if _, err := ParseRSAPrivateKey(data, nil); !errors.Is(err, ErrUnknownKeyFormat) {
+		t.Fatalf("expect ErrUnknownKeyFormat, got %v", err)
+	}
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	if _, err := ParseRSAPrivateKey(der, nil); !errors.Is(err, ErrNotRSAKey) {
+		t.Fatalf("expect ErrNotRSAKey, got %v", err)
+	}
+
+	if _, err := LoadRSAPrivateKey("testdata/missing.pem", nil); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expect ErrNotExist, got %v", err)
+	}
+}
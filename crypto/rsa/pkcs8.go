@@ -0,0 +1,229 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// RFC 5208 / RFC 8018 / RFC 7914 中的 ASN.1 结构
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// 解析口令加密私钥时的成本上限，防止恶意文件耗尽 CPU 或内存。
+// scrypt 按 128·N·r·p 字节限制总成本
+const (
+	maxPBKDF2Iterations = 10_000_000
+	maxScryptN          = 1 << 22
+	maxScryptCost       = 1 << 30
+)
+
+// decryptPKCS8 解密 PBES2（PBKDF2 或 scrypt + AES-CBC）加密的 PKCS#8 私钥
+func decryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) != 0 {
+		return nil, ErrUnknownKeyFormat
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: unsupported encryption %v", ErrUnknownKeyFormat, info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, ErrUnknownKeyFormat
+	}
+
+	var keyLen int
+	switch enc := params.EncryptionScheme.Algorithm; {
+	case enc.Equal(oidAES128CBC):
+		keyLen = 16
+	case enc.Equal(oidAES192CBC):
+		keyLen = 24
+	case enc.Equal(oidAES256CBC):
+		keyLen = 32
+	default:
+		return nil, fmt.Errorf("%w: unsupported cipher %v", ErrUnknownKeyFormat, enc)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, ErrUnknownKeyFormat
+	}
+
+	key, err := deriveKEK(params.KeyDerivationFunc, passphrase, keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	data := info.EncryptedData
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, ErrUnknownKeyFormat
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+
+	// 口令错误时填充几乎总是无效的，填充恰好有效的情况由随后的 DER 解析发现
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(out[len(out)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, ErrWrongPassphrase
+	}
+	out = out[:len(out)-pad]
+
+	if _, err := x509.ParsePKCS8PrivateKey(out); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return out, nil
+}
+
+func deriveKEK(kdf pkix.AlgorithmIdentifier, passphrase []byte, keyLen int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var p pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err != nil {
+			return nil, ErrUnknownKeyFormat
+		}
+		if p.IterationCount <= 0 || p.IterationCount > maxPBKDF2Iterations || (p.KeyLength != 0 && p.KeyLength != keyLen) {
+			return nil, fmt.Errorf("%w: bad pbkdf2 parameters", ErrUnknownKeyFormat)
+		}
+
+		var h func() hash.Hash
+		switch prf := p.PRF.Algorithm; {
+		case len(prf) == 0, prf.Equal(oidHMACWithSHA1):
+			h = sha1.New
+		case prf.Equal(oidHMACWithSHA256):
+			h = sha256.New
+		case prf.Equal(oidHMACWithSHA512):
+			h = sha512.New
+		default:
+			return nil, fmt.Errorf("%w: unsupported prf %v", ErrUnknownKeyFormat, prf)
+		}
+
+		return pbkdf2.Key(passphrase, p.Salt, p.IterationCount, keyLen, h), nil
+
+	case kdf.Algorithm.Equal(oidScrypt):
+		var p scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &p); err != nil {
+			return nil, ErrUnknownKeyFormat
+		}
+		n, r, par := p.CostParameter, p.BlockSize, p.ParallelizationParameter
+		if n <= 1 || n > maxScryptN || r <= 0 || par <= 0 || (p.KeyLength != 0 && p.KeyLength != keyLen) {
+			return nil, fmt.Errorf("%w: bad scrypt parameters", ErrUnknownKeyFormat)
+		}
+		if uint64(r)*uint64(par) > maxScryptCost/128/uint64(n) {
+			return nil, fmt.Errorf("%w: scrypt parameters too costly", ErrUnknownKeyFormat)
+		}
+
+		key, err := scrypt.Key(passphrase, p.Salt, n, r, par, keyLen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+		}
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported kdf %v", ErrUnknownKeyFormat, kdf.Algorithm)
+}
+
+// pkcs8Iterations 是加密私钥时 PBKDF2-HMAC-SHA256 的迭代次数
+const pkcs8Iterations = 600_000
+
+// encryptPKCS8 使用 PBES2（PBKDF2-HMAC-SHA256 + AES-256-CBC）加密 PKCS#8 私钥，
+// 输出可以被 openssl 和 decryptPKCS8 读取
+func encryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pkcs8Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2.Key(passphrase, salt, pkcs8Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pad := aes.BlockSize - len(der)%aes.BlockSize
+	data := append(append([]byte{}, der...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: data,
+	})
+}
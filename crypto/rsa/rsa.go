@@ -6,12 +6,8 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/sha512"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"fmt"
-	"os"
-	"sync"
 )
 
 type RSAKey struct {
@@ -19,11 +15,25 @@ type RSAKey struct {
 	pub  *rsa.PublicKey
 }
 
-const defaultRSAKeySize = 4096
+const (
+	defaultRSAKeySize = 4096
+	minRSAKeySize     = 2048
+)
+
+var ErrKeySize = errors.New("rsa key size must be at least 2048 bits")
 
 func GenRSAKey() (*RSAKey, error) {
+	return GenRSAKeyWithSize(defaultRSAKeySize)
+}
+
+// GenRSAKeyWithSize 生成指定位数的密钥，不小于 2048 位
+func GenRSAKeyWithSize(bits int) (*RSAKey, error) {
+	if bits < minRSAKeySize {
+		return nil, ErrKeySize
+	}
+
 	// 生成私钥
-	privateKey, err := rsa.GenerateKey(rand.Reader, defaultRSAKeySize)
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
 	if err != nil {
 		return nil, errors.New("gen rsa key failed")
 	}
@@ -37,59 +47,6 @@ func GenRSAKey() (*RSAKey, error) {
 	}, nil
 }
 
-func (c *RSAKey) SaveKey(Path string) {
-	wg := sync.WaitGroup{}
-
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		file, err := os.Create(Path + "prv.pem")
-		if err != nil {
-			fmt.Println("Failed to create key file:", err)
-			return
-		}
-		defer file.Close()
-
-		// 将私钥编码为PEM格式
-		privateKeyPEM := pem.EncodeToMemory(
-			&pem.Block{
-				Type:  "RSA PRIVATE KEY",
-				Bytes: x509.MarshalPKCS1PrivateKey(c.priv),
-			},
-		)
-
-		file.Write(privateKeyPEM)
-	}()
-
-	go func() {
-		defer wg.Done()
-		file, err := os.Create(Path + "pub.pem")
-		if err != nil {
-			fmt.Println("Failed to create key file:", err)
-			return
-		}
-
-		defer file.Close()
-
-		// 将公钥编码为PKIX格式的PEM
-		pubKeyBytes, err := x509.MarshalPKIXPublicKey(c.pub)
-		if err != nil {
-			fmt.Println("Failed to marshal public key:", err)
-			return
-		}
-		publicKeyPEM := pem.EncodeToMemory(
-			&pem.Block{
-				Type:  "PUBLIC KEY",
-				Bytes: pubKeyBytes,
-			},
-		)
-		file.Write(publicKeyPEM)
-	}()
-
-	wg.Wait()
-}
-
 func (c *RSAKey) Encrypt(msg string) []byte {
 	text, err := rsa.EncryptOAEP(
 		sha256.New(),
@@ -105,7 +62,13 @@ func (c *RSAKey) Encrypt(msg string) []byte {
 	return text
 }
 
+// Decrypt 使用 OAEP 解密，失败时返回空串；只有公钥时直接返回空串。
+// 需要区分错误时请使用 OpenEnvelope
 func (c *RSAKey) Decrypt(cipher []byte) string {
+	if c.priv == nil {
+		return ""
+	}
+
 	plaintext, err := rsa.DecryptOAEP(
 		sha256.New(),
 		rand.Reader,
@@ -122,7 +85,14 @@ func (c *RSAKey) Decrypt(cipher []byte) string {
 	return string(plaintext)
 }
 
+// Sign 使用 SHA-512 PSS 签名，返回的 string 是原始字节。
+//
+// Deprecated: 使用 SignBytes 或 SignBase64
 func (c *RSAKey) Sign(msg string) (string, error) {
+	if c.priv == nil {
+		return "", ErrNoPrivateKey
+	}
+
 	hash := sha512.Sum512([]byte(msg))
 
 	signature, err := rsa.SignPSS(
@@ -133,6 +103,7 @@ func (c *RSAKey) Sign(msg string) (string, error) {
 	return string(signature), err
 }
 
+// CheckSign 校验 Sign 的输出，pub 可以通过 RSAKey.PublicKey 获得
 func CheckSign(msg, signature string, pub *rsa.PublicKey) bool {
 	hash := sha512.Sum512([]byte(msg))
 
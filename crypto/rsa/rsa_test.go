@@ -8,8 +8,9 @@ import (
 func TestGenRSAKey(t *testing.T) {
 	keys, _ := GenRSAKey()
 
-	keys.SaveKey("./")
-
+	if err := keys.SaveKey(t.TempDir(), nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 }
 
 func TestRSA(t *testing.T) {
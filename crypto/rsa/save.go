@@ -0,0 +1,178 @@
+package rsa
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	privateKeyFile = "prv.pem"
+	publicKeyFile  = "pub.pem"
+)
+
+type SaveOptions struct {
+	// Overwrite 为 false 时目标文件已存在会返回 fs.ErrExist
+	Overwrite bool
+
+	// Passphrase 非空时私钥以加密的 PKCS#8 保存，可由 LoadRSAPrivateKey 或 openssl 读取
+	Passphrase []byte
+}
+
+// SaveKey 将私钥和公钥分别写入 dir 下的 prv.pem 和 pub.pem，只有公钥时只写 pub.pem。
+// 不允许覆盖时会先确认两个文件都不存在，避免只写入一半
+func (c *RSAKey) SaveKey(dir string, opts *SaveOptions) error {
+	if opts == nil {
+		opts = &SaveOptions{}
+	}
+
+	privPath := filepath.Join(dir, privateKeyFile)
+	pubPath := filepath.Join(dir, publicKeyFile)
+
+	if !opts.Overwrite {
+		for _, path := range []string{privPath, pubPath} {
+			if path == privPath && c.priv == nil {
+				continue
+			}
+			if _, err := os.Lstat(path); err == nil {
+				return fmt.Errorf("%s: %w", path, fs.ErrExist)
+			}
+		}
+	}
+
+	if c.priv != nil {
+		if err := c.SavePrivateKey(privPath, opts); err != nil {
+			return err
+		}
+	}
+
+	return c.SavePublicKey(pubPath, opts)
+}
+
+// SavePrivateKey 以 0600 权限原子地写入私钥 PEM
+func (c *RSAKey) SavePrivateKey(path string, opts *SaveOptions) error {
+	if opts == nil {
+		opts = &SaveOptions{}
+	}
+
+	data, err := c.MarshalPrivateKeyPEM(opts.Passphrase)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0o600, opts.Overwrite)
+}
+
+// SavePublicKey 以 0644 权限原子地写入 PKIX 公钥 PEM
+func (c *RSAKey) SavePublicKey(path string, opts *SaveOptions) error {
+	if opts == nil {
+		opts = &SaveOptions{}
+	}
+
+	data, err := c.MarshalPublicKeyPEM()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0o644, opts.Overwrite)
+}
+
+// MarshalPrivateKeyPEM 编码私钥。passphrase 为空时输出 PKCS#1（RSA PRIVATE KEY），
+// 否则输出 PBES2 加密的 PKCS#8（ENCRYPTED PRIVATE KEY）
+func (c *RSAKey) MarshalPrivateKeyPEM(passphrase []byte) ([]byte, error) {
+	if c.priv == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	if len(passphrase) == 0 {
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(c.priv),
+		}), nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(c.priv)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := encryptPKCS8(der, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "ENCRYPTED PRIVATE KEY",
+		Bytes: encrypted,
+	}), nil
+}
+
+// MarshalPublicKeyPEM 将公钥编码为 PKIX 格式的 PEM
+func (c *RSAKey) MarshalPublicKeyPEM() ([]byte, error) {
+	if c.pub == nil {
+		return nil, ErrNoPublicKey
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(c.pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: der,
+	}), nil
+}
+
+// writeFileAtomic 先写同目录下的临时文件并 fsync，再改名到目标路径，
+// 读者不会看到写了一半的文件。不允许覆盖时用硬链接代替改名，目标已存在则失败
+func writeFileAtomic(path string, data []byte, perm os.FileMode, overwrite bool) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if overwrite {
+		err = os.Rename(tmp.Name(), path)
+	} else {
+		err = os.Link(tmp.Name(), path)
+	}
+	if err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir 持久化目录项，保证改名在断电后仍然可见
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,170 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Scheme 是 RSA 签名的填充方案
+type Scheme int
+
+const (
+	SchemePSS Scheme = iota
+	SchemePKCS1v15
+)
+
+var (
+	ErrUnsupportedHash = errors.New("unsupported hash function")
+	ErrInvalidDigest   = errors.New("digest length does not match the hash function")
+	ErrVerification    = errors.New("rsa signature verification failed")
+)
+
+// SignOptions 配置签名参数，nil 等价于 SHA-512 PSS，与 Sign 一致
+type SignOptions struct {
+	// Hash 默认为 crypto.SHA512
+	Hash   crypto.Hash
+	Scheme Scheme
+
+	// SaltLength 为 PSS 的盐长度：0（rsa.PSSSaltLengthAuto）签名时取最大值、验签时自动检测，
+	// rsa.PSSSaltLengthEqualsHash 与哈希长度相同
+	SaltLength int
+}
+
+func (o *SignOptions) hash() (crypto.Hash, error) {
+	h := crypto.SHA512
+	if o != nil && o.Hash != 0 {
+		h = o.Hash
+	}
+
+	if !h.Available() {
+		return 0, fmt.Errorf("%w: %v", ErrUnsupportedHash, h)
+	}
+	return h, nil
+}
+
+// SignBytes 对消息签名
+func (c *RSAKey) SignBytes(msg []byte, opts *SignOptions) ([]byte, error) {
+	digest, err := digestOf(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SignDigest(digest, opts)
+}
+
+// SignDigest 对已经计算好的摘要签名，摘要长度必须与 opts.Hash 一致
+func (c *RSAKey) SignDigest(digest []byte, opts *SignOptions) ([]byte, error) {
+	if c.priv == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	h, err := opts.hash()
+	if err != nil {
+		return nil, err
+	}
+	if len(digest) != h.Size() {
+		return nil, ErrInvalidDigest
+	}
+
+	if opts != nil && opts.Scheme == SchemePKCS1v15 {
+		return rsa.SignPKCS1v15(nil, c.priv, h, digest)
+	}
+
+	return rsa.SignPSS(rand.Reader, c.priv, h, digest, pssOptions(h, opts))
+}
+
+// VerifyBytes 校验消息的签名，opts 必须与签名时一致
+func (c *RSAKey) VerifyBytes(msg, sig []byte, opts *SignOptions) error {
+	digest, err := digestOf(msg, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.VerifyDigest(digest, sig, opts)
+}
+
+// VerifyDigest 校验摘要的签名
+func (c *RSAKey) VerifyDigest(digest, sig []byte, opts *SignOptions) error {
+	if c.pub == nil {
+		return ErrNoPublicKey
+	}
+
+	h, err := opts.hash()
+	if err != nil {
+		return err
+	}
+	if len(digest) != h.Size() {
+		return ErrInvalidDigest
+	}
+
+	if opts != nil && opts.Scheme == SchemePKCS1v15 {
+		err = rsa.VerifyPKCS1v15(c.pub, h, digest, sig)
+	} else {
+		err = rsa.VerifyPSS(c.pub, h, digest, sig, pssOptions(h, opts))
+	}
+	if err != nil {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+// SignBase64 返回标准 base64 编码的签名
+func (c *RSAKey) SignBase64(msg []byte, opts *SignOptions) (string, error) {
+	sig, err := c.SignBytes(msg, opts)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// SignHex 返回 16 进制编码的签名
+func (c *RSAKey) SignHex(msg []byte, opts *SignOptions) (string, error) {
+	sig, err := c.SignBytes(msg, opts)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+// VerifyBase64 校验 SignBase64 的输出
+func (c *RSAKey) VerifyBase64(msg []byte, sig string, opts *SignOptions) error {
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+	return c.VerifyBytes(msg, raw, opts)
+}
+
+// VerifyHex 校验 SignHex 的输出
+func (c *RSAKey) VerifyHex(msg []byte, sig string, opts *SignOptions) error {
+	raw, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+	return c.VerifyBytes(msg, raw, opts)
+}
+
+func digestOf(msg []byte, opts *SignOptions) ([]byte, error) {
+	h, err := opts.hash()
+	if err != nil {
+		return nil, err
+	}
+
+	hh := h.New()
+	hh.Write(msg)
+	return hh.Sum(nil), nil
+}
+
+func pssOptions(h crypto.Hash, opts *SignOptions) *rsa.PSSOptions {
+	o := &rsa.PSSOptions{Hash: h}
+	if opts != nil {
+		o.SaltLength = opts.SaltLength
+	}
+	return o
+}
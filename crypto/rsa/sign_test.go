@@ -0,0 +1,156 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// openssl dgst -sha256 -sign testdata/pkcs8.pem
+const opensslPKCS1v15 = "NEyFPmTIBiRxrxD1PxkgCoIKRrkvhltqoFwQ254LBo4reE+1Yjcf/c8FfAm4vEARizgf5UtZnXDIY43qeu2/dCH1aWBTQ++ielHnHyNFOHd/GdxoY4T0w4kXVZ044feTsAOWQMDJaj7wobepzIfahCw3lq5azyo6hkJ/iJpVSM7He+6FtRP4Ma6PYRfxZBURjus6upg1Xa24fFp+QfNpmzVYE7ZtYQnGCvsA3Bt9sIiU2vUchIQmpAJZ1aKU8ryJUmO99VGpBWIInMPnHH1gmeS58tW3FvHe2Ano1ZTxP7BxotuRt9yjljAYyne7jn5FeStb7Qy3OhDlPpfibYJOVg=="
+
+// openssl dgst -sha512 -sigopt rsa_padding_mode:pss -sigopt rsa_pss_saltlen:digest -sign testdata/pkcs8.pem
+const opensslPSS = "ExJSlI6i5vFX8s/fIg2WsoEnLLTtDKQR8EIowgT5f28awVmdwBxN/Jx8wfESqvwiN66CILxd+Zw0LtYR3KlCpiBk1B61sFxKMvGBNtbyaT/J0nqhLO/U2GN1qsXy/p8Rk2Gw2BpbyqI7qVTSSULFxHuw+AO+eInDNT+HDZtNOG5JU0ygHGHYzSvXdpMjn9yRhItlgC82XQeDe6rUAPHDEZivWyBEQGNAOcVSQhaU/fZ36saS9vTfgreLDhoPggSigcb9R6vjUTJbPVVHlIhyltQptPEghJIeUHSpAxblcEH0ulCliTFJT3g01orW2kBn1cvfYWJJyWZvStkbM17/gQ=="
+
+func TestSignBytes(t *testing.T) {
+	key, err := LoadRSAPrivateKey("testdata/pkcs8.pem", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	pub := NewRSAPublicKey(key.PublicKey())
+	msg := []byte("hello")
+
+	for _, opts := range []*SignOptions{
+		nil,
+		{Hash: crypto.SHA256},
+		{Hash: crypto.SHA384, SaltLength: rsa.PSSSaltLengthEqualsHash},
+		{Hash: crypto.SHA256, Scheme: SchemePKCS1v15},
+	} {
+		sig, err := key.SignBytes(msg, opts)
+		if err != nil {
+			t.Fatalf("%+v: %v", opts, err)
+		}
+		if err := pub.VerifyBytes(msg, sig, opts); err != nil {
+			t.Fatalf("%+v: %v", opts, err)
+		}
+		if err := pub.VerifyBytes([]byte("hellO"), sig, opts); !errors.Is(err, ErrVerification) {
+			t.Fatalf("expect ErrVerification, got %v", err)
+		}
+	}
+
+	// Sign 的输出可以用 VerifyBytes 校验
+	legacy, _ := key.Sign("hello")
+	if err := pub.VerifyBytes(msg, []byte(legacy), nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !CheckSign("hello", legacy, key.PublicKey()) {
+		t.Fatalf("bad")
+	}
+}
+
+func TestSignBytes_openssl(t *testing.T) {
+	key, err := LoadRSAPrivateKey("testdata/pkcs8.pem", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	msg := []byte("hello")
+
+	// PKCS#1 v1.5 是确定性的
+	opts := &SignOptions{Hash: crypto.SHA256, Scheme: SchemePKCS1v15}
+	sig, err := key.SignBase64(msg, opts)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if sig != opensslPKCS1v15 {
+		t.Fatalf("bad: %s", sig)
+	}
+
+	for _, opts := range []*SignOptions{nil, {SaltLength: rsa.PSSSaltLengthEqualsHash}} {
+		if err := key.VerifyBase64(msg, opensslPSS, opts); err != nil {
+			t.Fatalf("%+v: %v", opts, err)
+		}
+	}
+}
+
+func TestSignDigest(t *testing.T) {
+	key, err := LoadRSAPrivateKey("testdata/pkcs8.pem", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	opts := &SignOptions{Hash: crypto.SHA256, Scheme: SchemePKCS1v15}
+	digest := sha256.Sum256([]byte("hello"))
+
+	sig, err := key.SignDigest(digest[:], opts)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if base64.StdEncoding.EncodeToString(sig) != opensslPKCS1v15 {
+		t.Fatalf("bad: %x", sig)
+	}
+	if err := key.VerifyDigest(digest[:], sig, opts); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := key.SignDigest(digest[:16], opts); !errors.Is(err, ErrInvalidDigest) {
+		t.Fatalf("expect ErrInvalidDigest, got %v", err)
+	}
+	if _, err := key.SignDigest(digest[:], nil); !errors.Is(err, ErrInvalidDigest) {
+		t.Fatalf("expect ErrInvalidDigest, got %v", err)
+	}
+}
+
+func TestSignHex(t *testing.T) {
+	key, err := LoadRSAPrivateKey("testdata/pkcs8.pem", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	sig, err := key.SignHex([]byte("hello"), nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := key.VerifyHex([]byte("hello"), sig, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := key.VerifyHex([]byte("hello"), "zz", nil); err == nil {
+		t.Fatalf("expect decode error")
+	}
+}
+
+func TestSignOptions_invalid(t *testing.T) {
+	key, err := LoadRSAPrivateKey("testdata/pkcs8.pem", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := key.SignBytes([]byte("hello"), &SignOptions{Hash: crypto.MD4}); !errors.Is(err, ErrUnsupportedHash) {
+		t.Fatalf("expect ErrUnsupportedHash, got %v", err)
+	}
+
+	pub := NewRSAPublicKey(key.PublicKey())
+	if _, err := pub.SignBytes([]byte("hello"), nil); !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("expect ErrNoPrivateKey, got %v", err)
+	}
+
+	if err := (&RSAKey{}).VerifyBytes([]byte("hello"), nil, nil); !errors.Is(err, ErrNoPublicKey) {
+		t.Fatalf("expect ErrNoPublicKey, got %v", err)
+	}
+}
+
+func TestGenRSAKeyWithSize(t *testing.T) {
+	if _, err := GenRSAKeyWithSize(1024); !errors.Is(err, ErrKeySize) {
+		t.Fatalf("expect ErrKeySize, got %v", err)
+	}
+
+	key, err := GenRSAKeyWithSize(2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if key.PublicKey().N.BitLen() != 2048 {
+		t.Fatalf("bad: %d", key.PublicKey().N.BitLen())
+	}
+}
@@ -0,0 +1,322 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Share is a self-describing share of a secret split with SplitShares.
+//
+// The binary encoding is
+//
+//	version(1) || scheme(1) || id(8) || fingerprint(4) ||
+//	threshold(2) || parts(2) || index(2) || value || checksum(4)
+//
+// where checksum is the first 4 bytes of SHA-256 over everything before it.
+// The text forms (base64, hex and mnemonic words) all wrap this encoding.
+type Share struct {
+	Scheme Scheme
+
+	// ID is random and shared by every share of one split, so shares of
+	// different secrets are never mixed up
+	ID [8]byte
+
+	// Fingerprint is a truncated HMAC of the secret keyed by ID, used to
+	// confirm that the combined secret is the original one. It narrows down
+	// guesses of a low entropy secret, so only split high entropy secrets
+	// (keys, not passwords) if shares may be exposed.
+	Fingerprint [4]byte
+
+	// Parts is the number of shares created by the split,
+	// shares added later with AddShare keep it
+	Threshold int
+	Parts     int
+
+	// Index is the x coordinate of the share
+	Index int
+	Value []byte
+}
+
+// Scheme identifies how the share value was computed
+type Scheme byte
+
+const (
+	// SchemeGF256 is the byte-wise scheme of Split over GF(2^8)
+	SchemeGF256 Scheme = 0x01
+)
+
+const (
+	shareVersion = 0x01
+
+	shareHeaderSize   = 1 + 1 + 8 + 4 + 2 + 2 + 2
+	shareChecksumSize = 4
+
+	fingerprintDST = "tools/crypto/shamir fingerprint v1"
+)
+
+var (
+	ErrInvalidShareEncoding = errors.New("invalid share encoding")
+	ErrShareChecksum        = errors.New("share checksum mismatch")
+	ErrUnsupportedVersion   = errors.New("unsupported share version")
+	ErrUnsupportedScheme    = errors.New("unsupported share scheme")
+	ErrShareMismatch        = errors.New("shares belong to different secrets")
+	ErrFingerprintMismatch  = errors.New("combined secret does not match the fingerprint")
+)
+
+//go:embed wordlist/english.txt
+var englishWords string
+
+var (
+	// wordlist is the 2048 word BIP39 English list
+	wordlist  = strings.Fields(englishWords)
+	wordIndex = func() map[string]int {
+		m := make(map[string]int, len(wordlist))
+		for i, w := range wordlist {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+// SplitShares is like Split but returns self-describing shares
+func SplitShares(secret []byte, parts, threshold int) ([]*Share, error) {
+	return SplitSharesWithOptions(secret, parts, threshold, nil)
+}
+
+// CombineShares checks that the shares belong to the same split and that
+// there are at least threshold of them, combines them and verifies the
+// result against the fingerprint.
+func CombineShares(shares []*Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("less than two parts cannot be used to reconstruct the secret")
+	}
+
+	first := shares[0]
+	raw := make([][]byte, len(shares))
+	for i, s := range shares {
+		if err := s.validate(); err != nil {
+			return nil, err
+		}
+		if s.ID != first.ID || s.Fingerprint != first.Fingerprint || s.Scheme != first.Scheme ||
+			s.Threshold != first.Threshold || s.Parts != first.Parts || len(s.Value) != len(first.Value) {
+			return nil, ErrShareMismatch
+		}
+
+		raw[i] = append(append([]byte{}, s.Value...), byte(s.Index))
+	}
+
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("need %d shares, got %d", first.Threshold, len(shares))
+	}
+
+	if first.Scheme == SchemeGF65536 {
+		return combine16(shares)
+	}
+
+	secret, err := Combine(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	fp := fingerprint(first.ID, secret)
+	if !hmac.Equal(fp[:], first.Fingerprint[:]) {
+		return nil, ErrFingerprintMismatch
+	}
+
+	return secret, nil
+}
+
+// MarshalBinary encodes the share with its header and checksum
+func (s *Share) MarshalBinary() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, shareHeaderSize+len(s.Value)+shareChecksumSize)
+	out = append(out, shareVersion, byte(s.Scheme))
+	out = append(out, s.ID[:]...)
+	out = append(out, s.Fingerprint[:]...)
+	out = binary.BigEndian.AppendUint16(out, uint16(s.Threshold))
+	out = binary.BigEndian.AppendUint16(out, uint16(s.Parts))
+	out = binary.BigEndian.AppendUint16(out, uint16(s.Index))
+	out = append(out, s.Value...)
+
+	sum := sha256.Sum256(out)
+	return append(out, sum[:shareChecksumSize]...), nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary
+func (s *Share) UnmarshalBinary(data []byte) error {
+	if len(data) < shareHeaderSize+1+shareChecksumSize {
+		return ErrInvalidShareEncoding
+	}
+
+	body, checksum := data[:len(data)-shareChecksumSize], data[len(data)-shareChecksumSize:]
+	sum := sha256.Sum256(body)
+	if !bytes.Equal(sum[:shareChecksumSize], checksum) {
+		return ErrShareChecksum
+	}
+	if body[0] != shareVersion {
+		return ErrUnsupportedVersion
+	}
+
+	out := Share{Scheme: Scheme(body[1])}
+	copy(out.ID[:], body[2:10])
+	copy(out.Fingerprint[:], body[10:14])
+	out.Threshold = int(binary.BigEndian.Uint16(body[14:]))
+	out.Parts = int(binary.BigEndian.Uint16(body[16:]))
+	out.Index = int(binary.BigEndian.Uint16(body[18:]))
+	out.Value = append([]byte{}, body[shareHeaderSize:]...)
+
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	*s = out
+	return nil
+}
+
+// ParseShare decodes a binary share
+func ParseShare(data []byte) (*Share, error) {
+	var s Share
+	if err := s.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Base64 returns the share as standard base64
+func (s *Share) Base64() (string, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Hex returns the share as lower case hex
+func (s *Share) Hex() (string, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// Mnemonic returns the share as words from the BIP39 English list, each
+// word carrying 11 bits. Unlike BIP39 the words encode the whole share
+// including its header, and the share checksum replaces the BIP39 one.
+func (s *Share) Mnemonic() (string, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	var words []string
+	var acc, bits uint
+	for _, b := range data {
+		acc = acc<<8 | uint(b)
+		bits += 8
+		for bits >= 11 {
+			bits -= 11
+			words = append(words, wordlist[acc>>bits&0x7ff])
+		}
+	}
+	if bits > 0 {
+		// zero pad the last word
+		words = append(words, wordlist[acc<<(11-bits)&0x7ff])
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// ShareFromBase64 decodes the output of Share.Base64
+func ShareFromBase64(s string) (*Share, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidShareEncoding, err)
+	}
+	return ParseShare(data)
+}
+
+// ShareFromHex decodes the output of Share.Hex
+func ShareFromHex(s string) (*Share, error) {
+	data, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidShareEncoding, err)
+	}
+	return ParseShare(data)
+}
+
+// ShareFromMnemonic decodes the output of Share.Mnemonic. Words are
+// matched case-insensitively and may be separated by any white space.
+func ShareFromMnemonic(s string) (*Share, error) {
+	words := strings.Fields(strings.ToLower(s))
+
+	var data []byte
+	var acc, bits uint
+	for _, w := range words {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidShareEncoding, w)
+		}
+
+		acc = acc<<11 | uint(idx)
+		bits += 11
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+
+	// the padding bits must be zero
+	if acc&(1<<bits-1) != 0 {
+		return nil, ErrInvalidShareEncoding
+	}
+
+	// With 8 to 10 bits of padding the last decoded byte is padding as well,
+	// the share checksum tells the two readings apart
+	if bits <= 2 && len(data) > 0 && data[len(data)-1] == 0 {
+		if share, err := ParseShare(data[:len(data)-1]); err == nil {
+			return share, nil
+		}
+	}
+
+	return ParseShare(data)
+}
+
+func (s *Share) validate() error {
+	maxIndex, err := s.Scheme.maxIndex()
+	if err != nil {
+		return err
+	}
+	if s.Threshold < 2 || s.Parts < s.Threshold || s.Parts > maxIndex {
+		return fmt.Errorf("%w: threshold %d parts %d", ErrInvalidShareEncoding, s.Threshold, s.Parts)
+	}
+	if s.Index < 1 || s.Index > maxIndex || len(s.Value) == 0 {
+		return fmt.Errorf("%w: index %d", ErrInvalidShareEncoding, s.Index)
+	}
+	if s.Scheme == SchemeGF65536 && len(s.Value)%2 != 0 {
+		return fmt.Errorf("%w: odd value length", ErrInvalidShareEncoding)
+	}
+
+	return nil
+}
+
+func fingerprint(id [8]byte, secret []byte) [4]byte {
+	mac := hmac.New(sha256.New, id[:])
+	mac.Write([]byte(fingerprintDST))
+	mac.Write(secret)
+
+	var out [4]byte
+	copy(out[:], mac.Sum(nil))
+	return out
+}
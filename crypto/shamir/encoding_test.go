@@ -0,0 +1,188 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWordlist(t *testing.T) {
+	sum := sha256.Sum256([]byte(englishWords))
+	if hex.EncodeToString(sum[:]) != "2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda" {
+		t.Fatalf("bad: %x", sum)
+	}
+	if len(wordlist) != 2048 || len(wordIndex) != 2048 {
+		t.Fatalf("bad: %d", len(wordlist))
+	}
+}
+
+func TestSplitShares(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := SplitShares(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for _, s := range shares {
+		if s.Threshold != 3 || s.Parts != 5 || s.ID != shares[0].ID || len(s.Value) != len(secret) {
+			t.Fatalf("bad: %+v", s)
+		}
+	}
+
+	out, err := CombineShares(shares[1:4])
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(out, secret) {
+		t.Fatalf("bad: %q", out)
+	}
+
+	if _, err := CombineShares(shares[:2]); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	// shares of another split are rejected before combining
+	other, _ := SplitShares(secret, 5, 3)
+	if _, err := CombineShares([]*Share{shares[0], shares[1], other[2]}); !errors.Is(err, ErrShareMismatch) {
+		t.Fatalf("expect ErrShareMismatch, got %v", err)
+	}
+
+	// a corrupted value is caught by the fingerprint
+	bad := *shares[2]
+	bad.Value = append([]byte{}, bad.Value...)
+	bad.Value[0] ^= 1
+	if _, err := CombineShares([]*Share{shares[0], shares[1], &bad}); !errors.Is(err, ErrFingerprintMismatch) {
+		t.Fatalf("expect ErrFingerprintMismatch, got %v", err)
+	}
+}
+
+func TestShareEncoding(t *testing.T) {
+	// secret lengths cover every mnemonic padding length
+	for size := 1; size <= 16; size++ {
+		secret := bytes.Repeat([]byte{byte(size)}, size)
+
+		shares, err := SplitShares(secret, 3, 2)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		var decoded []*Share
+		for i, s := range shares {
+			b64, err := s.Base64()
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			hexStr, _ := s.Hex()
+			words, _ := s.Mnemonic()
+
+			for _, parse := range []func() (*Share, error){
+				func() (*Share, error) { return ShareFromBase64(b64) },
+				func() (*Share, error) { return ShareFromHex(hexStr) },
+				func() (*Share, error) { return ShareFromMnemonic(words) },
+				func() (*Share, error) { return ShareFromMnemonic(" " + strings.ToUpper(words) + "\n") },
+			} {
+				got, err := parse()
+				if err != nil {
+					t.Fatalf("size %d share %d: %v", size, i, err)
+				}
+				if got.Index != s.Index || got.ID != s.ID || !bytes.Equal(got.Value, s.Value) {
+					t.Fatalf("size %d share %d: bad: %+v", size, i, got)
+				}
+			}
+
+			got, _ := ShareFromMnemonic(words)
+			decoded = append(decoded, got)
+		}
+
+		out, err := CombineShares(decoded[1:])
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("size %d: bad: %x %v", size, out, err)
+		}
+	}
+}
+
+func TestShareEncoding_invalid(t *testing.T) {
+	shares, err := SplitShares([]byte("test"), 3, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	data, _ := shares[0].MarshalBinary()
+
+	for i := range data {
+		tampered := append([]byte{}, data...)
+		tampered[i] ^= 0x10
+		if _, err := ParseShare(tampered); !errors.Is(err, ErrShareChecksum) {
+			t.Fatalf("byte %d: expect ErrShareChecksum, got %v", i, err)
+		}
+	}
+
+	if _, err := ParseShare(data[:10]); !errors.Is(err, ErrInvalidShareEncoding) {
+		t.Fatalf("expect ErrInvalidShareEncoding, got %v", err)
+	}
+
+	words, _ := shares[0].Mnemonic()
+	list := strings.Fields(words)
+	if _, err := ShareFromMnemonic(strings.Join(append(list[:1:1], append([]string{"notaword"}, list[2:]...)...), " ")); !errors.Is(err, ErrInvalidShareEncoding) {
+		t.Fatalf("expect ErrInvalidShareEncoding, got %v", err)
+	}
+
+	// swapping two words breaks the checksum
+	list[0], list[1] = list[1], list[0]
+	if _, err := ShareFromMnemonic(strings.Join(list, " ")); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	if _, err := ShareFromHex("zz"); !errors.Is(err, ErrInvalidShareEncoding) {
+		t.Fatalf("expect ErrInvalidShareEncoding, got %v", err)
+	}
+
+	unknown := *shares[0]
+	unknown.Scheme = 0x7f
+	if _, err := unknown.MarshalBinary(); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Fatalf("expect ErrUnsupportedScheme, got %v", err)
+	}
+}
+
+func FuzzParseShare(f *testing.F) {
+	for _, scheme := range []Scheme{SchemeGF256, SchemeGF65536} {
+		shares, err := SplitSharesWithOptions([]byte("test"), 3, 2, &SplitOptions{Scheme: scheme})
+		if err != nil {
+			f.Fatalf("err: %v", err)
+		}
+		data, _ := shares[0].MarshalBinary()
+		f.Add(data)
+	}
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		share, err := ParseShare(data)
+		if err != nil {
+			return
+		}
+
+		// a share that parses encodes back to the same bytes
+		out, err := share.MarshalBinary()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Fatalf("bad: expect %x, got %x", data, out)
+		}
+
+		words, err := share.Mnemonic()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		decoded, err := ShareFromMnemonic(words)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out, _ := decoded.MarshalBinary(); !bytes.Equal(out, data) {
+			t.Fatalf("bad: expect %x, got %x", data, out)
+		}
+	})
+}
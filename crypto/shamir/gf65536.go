@@ -0,0 +1,159 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Arithmetic in GF(2^16) with the primitive reduction polynomial
+// x^16 + x^12 + x^3 + x + 1. Every element is a uint16 and the scheme works
+// on two bytes of the secret at a time, which allows up to 65535 parts.
+// Like the GF(2^8) code, multiplication avoids lookup tables and
+// secret-dependent branches.
+
+const gf16Poly = 0x100B
+
+// mult16 multiplies two numbers in GF(2^16)
+func mult16(a, b uint16) uint16 {
+	var r uint16
+	for i := 15; i >= 0; i-- {
+		r = (-(r >> 15) & gf16Poly) ^ (r << 1) ^ (-(b >> i & 1) & a)
+	}
+	return r
+}
+
+// inverse16 calculates the inverse of a number in GF(2^16) as a^(2^16-2)
+func inverse16(a uint16) uint16 {
+	// a^(2^k - 1) for k = 1..15 by square-and-multiply, then one more square
+	r := a
+	for i := 0; i < 14; i++ {
+		r = mult16(mult16(r, r), a)
+	}
+	return mult16(r, r)
+}
+
+// div16 divides two numbers in GF(2^16)
+func div16(a, b uint16) uint16 {
+	if b == 0 {
+		panic("divide by zero")
+	}
+	return mult16(a, inverse16(b))
+}
+
+// polynomial16 is a polynomial over GF(2^16)
+type polynomial16 []uint16
+
+func makePolynomial16(intercept uint16, degree int, random io.Reader) (polynomial16, error) {
+	buf := make([]byte, 2*degree)
+	if _, err := io.ReadFull(random, buf); err != nil {
+		return nil, err
+	}
+
+	p := make(polynomial16, degree+1)
+	p[0] = intercept
+	for i := 1; i <= degree; i++ {
+		p[i] = binary.BigEndian.Uint16(buf[2*(i-1):])
+	}
+	return p, nil
+}
+
+func (p polynomial16) evaluate(x uint16) uint16 {
+	out := p[len(p)-1]
+	for i := len(p) - 2; i >= 0; i-- {
+		out = mult16(out, x) ^ p[i]
+	}
+	return out
+}
+
+// interpolate16 returns the value at x of the polynomial through the samples
+func interpolate16(xs, ys []uint16, x uint16) uint16 {
+	var result uint16
+	for i, basis := range lagrangeCoefficients16(xs, x) {
+		result ^= mult16(ys[i], basis)
+	}
+	return result
+}
+
+// lagrangeCoefficients16 returns the Lagrange basis polynomial of each
+// sample evaluated at x
+func lagrangeCoefficients16(xs []uint16, x uint16) []uint16 {
+	out := make([]uint16, len(xs))
+	for i := range xs {
+		basis := uint16(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = mult16(basis, div16(x^xs[j], xs[i]^xs[j]))
+		}
+		out[i] = basis
+	}
+	return out
+}
+
+// split16 shares the secret, which must have an even length, at the given
+// x coordinates. Each returned value holds one big endian uint16 per two
+// bytes of the secret.
+func split16(secret []byte, xCoordinates []int, threshold int, random io.Reader) ([][]byte, error) {
+	out := make([][]byte, len(xCoordinates))
+	for i := range out {
+		out[i] = make([]byte, len(secret))
+	}
+
+	for idx := 0; idx < len(secret); idx += 2 {
+		p, err := makePolynomial16(binary.BigEndian.Uint16(secret[idx:]), threshold-1, random)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+		}
+
+		for i, x := range xCoordinates {
+			binary.BigEndian.PutUint16(out[i][idx:], p.evaluate(uint16(x)))
+		}
+	}
+
+	return out, nil
+}
+
+// interpolateValues16 evaluates at x the sharing given by the shares'
+// indices and values
+func interpolateValues16(indices []int, values [][]byte, x uint16) []byte {
+	xs := make([]uint16, len(indices))
+	for i, idx := range indices {
+		xs[i] = uint16(idx)
+	}
+
+	basis := lagrangeCoefficients16(xs, x)
+	out := make([]byte, len(values[0]))
+	for idx := 0; idx < len(out); idx += 2 {
+		var y uint16
+		for i, v := range values {
+			y ^= mult16(binary.BigEndian.Uint16(v[idx:]), basis[i])
+		}
+		binary.BigEndian.PutUint16(out[idx:], y)
+	}
+
+	return out
+}
+
+// pad16 pads the secret to an even length: 0x80 followed by a zero byte
+// when needed, so the padding can always be removed
+func pad16(secret []byte) []byte {
+	out := append(append([]byte{}, secret...), 0x80)
+	if len(out)%2 == 1 {
+		out = append(out, 0x00)
+	}
+	return out
+}
+
+func unpad16(padded []byte) ([]byte, error) {
+	n := len(padded)
+	switch {
+	case n >= 2 && padded[n-2] == 0x80 && padded[n-1] == 0x00:
+		return padded[:n-2], nil
+	case n >= 1 && padded[n-1] == 0x80:
+		return padded[:n-1], nil
+	default:
+		return nil, ErrInvalidShareEncoding
+	}
+}
@@ -0,0 +1,67 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestField16_inverse(t *testing.T) {
+	for i := 1; i < 1<<16; i++ {
+		a := uint16(i)
+		if mult16(a, inverse16(a)) != 1 {
+			t.Fatalf("bad: %d", a)
+		}
+	}
+}
+
+func TestField16_mult(t *testing.T) {
+	// x * x^15 = x^16 = x^12 + x^3 + x + 1
+	if out := mult16(2, 0x8000); out != 0x100B {
+		t.Fatalf("bad: %x", out)
+	}
+	if out := mult16(0x1234, 1); out != 0x1234 {
+		t.Fatalf("bad: %x", out)
+	}
+	if out := mult16(0x1234, 0); out != 0 {
+		t.Fatalf("bad: %x", out)
+	}
+	if out := div16(mult16(0x1234, 0xbeef), 0xbeef); out != 0x1234 {
+		t.Fatalf("bad: %x", out)
+	}
+}
+
+func TestPolynomial16(t *testing.T) {
+	p, err := makePolynomial16(0x4242, 2, bytes.NewReader([]byte{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if out := p.evaluate(0); out != 0x4242 {
+		t.Fatalf("bad: %x", out)
+	}
+
+	xs := []uint16{1, 300, 65535}
+	ys := []uint16{p.evaluate(1), p.evaluate(300), p.evaluate(65535)}
+	for _, x := range []uint16{0, 7, 1000} {
+		if out := interpolate16(xs, ys, x); out != p.evaluate(x) {
+			t.Fatalf("x=%d: bad: %x", x, out)
+		}
+	}
+}
+
+func TestPad16(t *testing.T) {
+	for _, secret := range [][]byte{{1}, {1, 2}, {0x80}, {0x80, 0x80}, {1, 0x80, 0}} {
+		padded := pad16(secret)
+		if len(padded)%2 != 0 {
+			t.Fatalf("bad: %x", padded)
+		}
+		out, err := unpad16(padded)
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %x %v", out, err)
+		}
+	}
+
+	if _, err := unpad16([]byte{1, 2}); err == nil {
+		t.Fatalf("expect error")
+	}
+}
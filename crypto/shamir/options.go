@@ -0,0 +1,249 @@
+package shamir
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// SchemeGF65536 works in GF(2^16) on two bytes of the secret at a time
+	// and supports up to 65535 parts
+	SchemeGF65536 Scheme = 0x02
+)
+
+// SplitOptions configures SplitSharesWithOptions
+type SplitOptions struct {
+	// Scheme defaults to SchemeGF256, or SchemeGF65536 for more than 255 parts
+	Scheme Scheme
+
+	// Indices are the x coordinates of the shares, one per part, distinct
+	// and between 1 and the scheme maximum. Random indices are used when nil.
+	Indices []int
+
+	// Rand is the source of all randomness, crypto/rand when nil. A fixed
+	// reader makes the output deterministic, which is only useful in tests.
+	Rand io.Reader
+}
+
+// maxIndex returns the largest x coordinate of the scheme
+func (s Scheme) maxIndex() (int, error) {
+	switch s {
+	case SchemeGF256:
+		return 255, nil
+	case SchemeGF65536:
+		return 65535, nil
+	default:
+		return 0, ErrUnsupportedScheme
+	}
+}
+
+// SplitSharesWithOptions is SplitShares with control over the field,
+// the share indices and the randomness source
+func SplitSharesWithOptions(secret []byte, parts, threshold int, opts *SplitOptions) ([]*Share, error) {
+	o := SplitOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Rand == nil {
+		o.Rand = rand.Reader
+	}
+	if o.Scheme == 0 {
+		o.Scheme = SchemeGF256
+		if parts > 255 {
+			o.Scheme = SchemeGF65536
+		}
+	}
+
+	maxIndex, err := o.Scheme.maxIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sanity check the input
+	if parts < threshold {
+		return nil, fmt.Errorf("parts cannot be less than threshold")
+	}
+	if parts > maxIndex {
+		return nil, fmt.Errorf("parts cannot exceed %d", maxIndex)
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2")
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("cannot split an empty secret")
+	}
+
+	indices := o.Indices
+	if indices == nil {
+		if indices, err = randomIndices(o.Rand, parts, maxIndex); err != nil {
+			return nil, err
+		}
+	} else if err := checkIndices(indices, parts, maxIndex); err != nil {
+		return nil, err
+	}
+
+	var id [8]byte
+	if _, err := io.ReadFull(o.Rand, id[:]); err != nil {
+		return nil, err
+	}
+
+	var values [][]byte
+	switch o.Scheme {
+	case SchemeGF256:
+		values, err = split(secret, indices, threshold, o.Rand)
+		for i := range values {
+			// drop the trailing x coordinate
+			values[i] = values[i][:len(secret)]
+		}
+	case SchemeGF65536:
+		values, err = split16(pad16(secret), indices, threshold, o.Rand)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fp := fingerprint(id, secret)
+	shares := make([]*Share, parts)
+	for i := range shares {
+		shares[i] = &Share{
+			Scheme:      o.Scheme,
+			ID:          id,
+			Fingerprint: fp,
+			Threshold:   threshold,
+			Parts:       parts,
+			Index:       indices[i],
+			Value:       values[i],
+		}
+	}
+
+	return shares, nil
+}
+
+// AddShare creates a share with the given index for an existing split from
+// at least threshold of its shares. The shares are combined and checked
+// against the fingerprint first, so the caller briefly holds the secret,
+// as anyone with threshold shares can. The caller must make sure index is
+// not used by a share that already exists elsewhere.
+func AddShare(shares []*Share, index int) (*Share, error) {
+	if _, err := CombineShares(shares); err != nil {
+		return nil, err
+	}
+
+	first := shares[0]
+	maxIndex, _ := first.Scheme.maxIndex()
+	if index < 1 || index > maxIndex {
+		return nil, fmt.Errorf("index must be between 1 and %d", maxIndex)
+	}
+
+	indices := make([]int, len(shares))
+	values := make([][]byte, len(shares))
+	for i, s := range shares {
+		if s.Index == index {
+			return nil, fmt.Errorf("index %d already in use", index)
+		}
+		indices[i] = s.Index
+		values[i] = s.Value
+	}
+
+	out := *first
+	switch first.Scheme {
+	case SchemeGF256:
+		xs := make([]uint8, len(indices))
+		for i, idx := range indices {
+			xs[i] = uint8(idx)
+		}
+
+		out.Value = make([]byte, len(first.Value))
+		for i, basis := range lagrangeCoefficients(xs, uint8(index)) {
+			mulAdd(out.Value, values[i], basis)
+		}
+	case SchemeGF65536:
+		out.Value = interpolateValues16(indices, values, uint16(index))
+	}
+	out.Index = index
+
+	return &out, nil
+}
+
+// combine16 reconstructs a SchemeGF65536 secret and checks its fingerprint
+func combine16(shares []*Share) ([]byte, error) {
+	indices := make([]int, len(shares))
+	values := make([][]byte, len(shares))
+	seen := map[int]bool{}
+	for i, s := range shares {
+		if seen[s.Index] {
+			return nil, fmt.Errorf("duplicate part detected")
+		}
+		seen[s.Index] = true
+		indices[i] = s.Index
+		values[i] = s.Value
+	}
+
+	secret, err := unpad16(interpolateValues16(indices, values, 0))
+	if err != nil {
+		return nil, ErrFingerprintMismatch
+	}
+
+	fp := fingerprint(shares[0].ID, secret)
+	if !hmac.Equal(fp[:], shares[0].Fingerprint[:]) {
+		return nil, ErrFingerprintMismatch
+	}
+
+	return secret, nil
+}
+
+// randomIndices picks n distinct x coordinates uniformly from [1, max]
+func randomIndices(random io.Reader, n, max int) ([]int, error) {
+	// partial Fisher-Yates shuffle of 1..max
+	pool := make([]int, max)
+	for i := range pool {
+		pool[i] = i + 1
+	}
+
+	for i := 0; i < n; i++ {
+		j, err := uniform(random, max-i)
+		if err != nil {
+			return nil, err
+		}
+		pool[i], pool[i+j] = pool[i+j], pool[i]
+	}
+
+	return pool[:n], nil
+}
+
+// uniform returns a uniform integer in [0, n) without modulo bias
+func uniform(random io.Reader, n int) (int, error) {
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(n)
+
+	var buf [4]byte
+	for {
+		if _, err := io.ReadFull(random, buf[:]); err != nil {
+			return 0, err
+		}
+		if v := uint64(binary.BigEndian.Uint32(buf[:])); v < limit {
+			return int(v % uint64(n)), nil
+		}
+	}
+}
+
+func checkIndices(indices []int, parts, max int) error {
+	if len(indices) != parts {
+		return fmt.Errorf("expect %d indices, got %d", parts, len(indices))
+	}
+
+	seen := map[int]bool{}
+	for _, idx := range indices {
+		if idx < 1 || idx > max {
+			return fmt.Errorf("index must be between 1 and %d, got %d", max, idx)
+		}
+		if seen[idx] {
+			return fmt.Errorf("duplicate index %d", idx)
+		}
+		seen[idx] = true
+	}
+
+	return nil
+}
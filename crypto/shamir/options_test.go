@@ -0,0 +1,238 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+// testReader is a deterministic stream of SHA-256(seed || counter) blocks
+type testReader struct {
+	seed    string
+	counter uint64
+	buf     []byte
+}
+
+func (r *testReader) Read(p []byte) (int, error) {
+	for len(r.buf) < len(p) {
+		h := sha256.New()
+		h.Write([]byte(r.seed))
+		h.Write(binary.BigEndian.AppendUint64(nil, r.counter))
+		r.buf = h.Sum(r.buf)
+		r.counter++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestSplitSharesWithOptions_largeField(t *testing.T) {
+	secret := []byte("an odd length secret")
+
+	shares, err := SplitSharesWithOptions(secret, 1000, 5, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if shares[0].Scheme != SchemeGF65536 || len(shares) != 1000 {
+		t.Fatalf("bad: %d %d", shares[0].Scheme, len(shares))
+	}
+
+	seen := map[int]bool{}
+	for _, s := range shares {
+		if s.Index < 1 || s.Index > 65535 || seen[s.Index] {
+			t.Fatalf("bad index: %d", s.Index)
+		}
+		seen[s.Index] = true
+	}
+
+	for _, subset := range [][]*Share{shares[:5], shares[995:], shares[100:200]} {
+		out, err := CombineShares(subset)
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %q %v", out, err)
+		}
+	}
+
+	if _, err := CombineShares(shares[:4]); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	// text encodings carry the larger indices
+	words, err := shares[999].Mnemonic()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	decoded, err := ShareFromMnemonic(words)
+	if err != nil || decoded.Index != shares[999].Index || decoded.Scheme != SchemeGF65536 {
+		t.Fatalf("bad: %+v %v", decoded, err)
+	}
+
+	bad := *shares[1]
+	bad.Value = append([]byte{}, bad.Value...)
+	bad.Value[3] ^= 1
+	if _, err := CombineShares([]*Share{shares[0], &bad, shares[2], shares[3], shares[4]}); !errors.Is(err, ErrFingerprintMismatch) {
+		t.Fatalf("expect ErrFingerprintMismatch, got %v", err)
+	}
+}
+
+func TestSplitSharesWithOptions_indices(t *testing.T) {
+	secret := []byte("test")
+
+	for _, scheme := range []Scheme{SchemeGF256, SchemeGF65536} {
+		indices := []int{1, 2, 3, 200}
+		if scheme == SchemeGF65536 {
+			indices[3] = 40000
+		}
+
+		shares, err := SplitSharesWithOptions(secret, 4, 2, &SplitOptions{Scheme: scheme, Indices: indices})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		for i, s := range shares {
+			if s.Index != indices[i] || s.Scheme != scheme {
+				t.Fatalf("bad: %+v", s)
+			}
+		}
+
+		out, err := CombineShares([]*Share{shares[3], shares[1]})
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %q %v", out, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		opts  *SplitOptions
+		parts int
+	}{
+		{&SplitOptions{Indices: []int{1, 2}}, 3},
+		{&SplitOptions{Indices: []int{1, 2, 2}}, 3},
+		{&SplitOptions{Indices: []int{0, 1, 2}}, 3},
+		{&SplitOptions{Indices: []int{1, 2, 256}}, 3},
+		{&SplitOptions{Scheme: SchemeGF256}, 256},
+		{&SplitOptions{Scheme: 0x7f}, 3},
+	} {
+		if _, err := SplitSharesWithOptions(secret, tc.parts, 2, tc.opts); err == nil {
+			t.Fatalf("%+v: expect error", tc.opts)
+		}
+	}
+}
+
+func TestSplitSharesWithOptions_deterministic(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	for _, scheme := range []Scheme{SchemeGF256, SchemeGF65536} {
+		split := func(seed string) []*Share {
+			shares, err := SplitSharesWithOptions(secret, 5, 3, &SplitOptions{Scheme: scheme, Rand: &testReader{seed: seed}})
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			return shares
+		}
+
+		a, b, c := split("seed"), split("seed"), split("other seed")
+		for i := range a {
+			ea, _ := a[i].MarshalBinary()
+			eb, _ := b[i].MarshalBinary()
+			ec, _ := c[i].MarshalBinary()
+			if !bytes.Equal(ea, eb) || bytes.Equal(ea, ec) {
+				t.Fatalf("bad: %x %x %x", ea, eb, ec)
+			}
+		}
+
+		out, err := CombineShares(a[2:])
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %q %v", out, err)
+		}
+	}
+}
+
+func TestAddShare(t *testing.T) {
+	secret := []byte("correct horse battery staple!")
+
+	for _, scheme := range []Scheme{SchemeGF256, SchemeGF65536} {
+		shares, err := SplitSharesWithOptions(secret, 3, 3, &SplitOptions{Scheme: scheme, Indices: []int{1, 2, 3}})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		added, err := AddShare(shares, 9)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if added.Index != 9 || added.ID != shares[0].ID || added.Parts != 3 {
+			t.Fatalf("bad: %+v", added)
+		}
+
+		out, err := CombineShares([]*Share{added, shares[0], shares[2]})
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %q %v", out, err)
+		}
+
+		if _, err := AddShare(shares, 2); err == nil {
+			t.Fatalf("expect error")
+		}
+		if _, err := AddShare(shares[:2], 10); err == nil {
+			t.Fatalf("expect error")
+		}
+	}
+}
+
+func TestRandomIndices(t *testing.T) {
+	indices, err := randomIndices(&testReader{seed: "x"}, 255, 255)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	seen := map[int]bool{}
+	for _, idx := range indices {
+		if idx < 1 || idx > 255 || seen[idx] {
+			t.Fatalf("bad: %v", indices)
+		}
+		seen[idx] = true
+	}
+}
+
+func FuzzSplitSharesWithOptions(f *testing.F) {
+	f.Add([]byte("test"), uint16(5), uint8(3), uint64(0), true)
+	f.Add([]byte{0x80}, uint16(2), uint8(2), uint64(1), false)
+	f.Add([]byte{0x80, 0x00}, uint16(300), uint8(4), uint64(2), true)
+
+	f.Fuzz(func(t *testing.T, secret []byte, parts uint16, threshold uint8, seed uint64, large bool) {
+		if len(secret) == 0 {
+			return
+		}
+
+		scheme, n := SchemeGF256, 2+int(parts)%19
+		if large {
+			scheme, n = SchemeGF65536, 2+int(parts)%299
+		}
+		k := 2 + int(threshold)%min(n-1, 10)
+
+		shares, err := SplitSharesWithOptions(secret, n, k, &SplitOptions{
+			Scheme: scheme,
+			Rand:   &testReader{seed: string(binary.BigEndian.AppendUint64(nil, seed))},
+		})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		r := rand.New(rand.NewPCG(seed, 1))
+		r.Shuffle(len(shares), func(i, j int) { shares[i], shares[j] = shares[j], shares[i] })
+		subset := shares[:k+r.IntN(min(n-k, 10)+1)]
+
+		out, err := CombineShares(subset)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %d of %d/%d: expect %x, got %x", len(subset), k, n, secret, out)
+		}
+
+		if _, err := CombineShares(subset[:k-1]); err == nil {
+			t.Fatalf("expect error")
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Refresh and resharing run as a protocol among share holders, the secret
+// is never reconstructed. Every holder runs the deal step on its own share
+// and sends each SubShare to the holder named by its To field over a
+// private, authenticated channel. After the apply step every holder must
+// erase its old share and the sub-shares it received.
+//
+// Both steps keep the ID and Fingerprint of the shares, so combining old
+// and new shares fails with ErrFingerprintMismatch or ErrShareMismatch.
+// Sub-shares are not verifiable: a holder that deals garbage is only
+// detected when the new shares are combined. Only SchemeGF256 shares
+// are supported.
+
+var ErrSubShareMismatch = errors.New("sub-shares do not belong to the same round")
+
+// SubShare is the message sent from holder From to holder To during a
+// refresh or reshare round
+type SubShare struct {
+	ID          [8]byte
+	Fingerprint [4]byte
+
+	// OldThreshold is the threshold of the dealing shares, Threshold and
+	// Parts describe the shares being created
+	OldThreshold int
+	Threshold    int
+	Parts        int
+
+	From  int
+	To    int
+	Value []byte
+}
+
+// DealRefresh starts a proactive refresh among holders, the indices of all
+// participating shares including this one. Each holder adds a random
+// sharing of zero to every share, so the new shares encode the same secret
+// and the old ones become useless. Holders left out of the list keep their
+// old shares, which no longer combine with the refreshed ones.
+func DealRefresh(share *Share, holders []int) ([]*SubShare, error) {
+	if err := validateGF256(share); err != nil {
+		return nil, err
+	}
+	if err := checkHolders(share, holders); err != nil {
+		return nil, err
+	}
+
+	// a sharing of zero at the holders' indices
+	values, err := split(make([]byte, len(share.Value)), holders, share.Threshold, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	subs := newSubShares(share, share.Threshold, share.Parts, holders)
+	for i, sub := range subs {
+		copy(sub.Value, values[i])
+	}
+
+	return subs, nil
+}
+
+// ApplyRefresh adds the sub-shares received from every holder to the share
+// and returns the refreshed share
+func ApplyRefresh(share *Share, holders []int, subs []*SubShare) (*Share, error) {
+	if err := validateGF256(share); err != nil {
+		return nil, err
+	}
+	if err := checkHolders(share, holders); err != nil {
+		return nil, err
+	}
+
+	from, err := checkSubShares(subs, share.Index, len(share.Value))
+	if err != nil {
+		return nil, err
+	}
+	expect := slices.Clone(holders)
+	slices.Sort(expect)
+	if !slices.Equal(from, expect) {
+		return nil, fmt.Errorf("%w: expect sub-shares from %v, got %v", ErrSubShareMismatch, holders, from)
+	}
+
+	first := subs[0]
+	if first.ID != share.ID || first.Fingerprint != share.Fingerprint ||
+		first.Threshold != share.Threshold || first.Parts != share.Parts {
+		return nil, ErrSubShareMismatch
+	}
+
+	out := *share
+	out.Value = append([]byte{}, share.Value...)
+	for _, sub := range subs {
+		for idx := range out.Value {
+			out.Value[idx] = add(out.Value[idx], sub.Value[idx])
+		}
+	}
+
+	return &out, nil
+}
+
+// DealReshare shares this holder's share to a new (threshold, parts)
+// configuration with indices 1..parts. At least the old threshold number of
+// holders must deal, and every new holder needs the sub-shares of all of
+// them.
+func DealReshare(share *Share, threshold, parts int) ([]*SubShare, error) {
+	if err := validateGF256(share); err != nil {
+		return nil, err
+	}
+	if parts < threshold {
+		return nil, fmt.Errorf("parts cannot be less than threshold")
+	}
+	if parts > 255 {
+		return nil, fmt.Errorf("parts cannot exceed 255")
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2")
+	}
+
+	to := make([]int, parts)
+	for i := range to {
+		to[i] = i + 1
+	}
+
+	values, err := split(share.Value, to, threshold, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	subs := newSubShares(share, threshold, parts, to)
+	for i, sub := range subs {
+		copy(sub.Value, values[i])
+	}
+
+	return subs, nil
+}
+
+// ApplyReshare combines the sub-shares a new holder received from the old
+// holders into its share of the new configuration
+func ApplyReshare(subs []*SubShare) (*Share, error) {
+	if len(subs) == 0 {
+		return nil, ErrSubShareMismatch
+	}
+
+	first := subs[0]
+	from, err := checkSubShares(subs, first.To, len(first.Value))
+	if err != nil {
+		return nil, err
+	}
+	if len(from) < first.OldThreshold {
+		return nil, fmt.Errorf("need sub-shares from %d holders, got %d", first.OldThreshold, len(from))
+	}
+
+	// Lagrange basis of each dealer at x = 0 over the dealing set
+	xs := make([]uint8, len(subs))
+	for i, sub := range subs {
+		xs[i] = uint8(sub.From)
+	}
+
+	out := &Share{
+		Scheme:      SchemeGF256,
+		ID:          first.ID,
+		Fingerprint: first.Fingerprint,
+		Threshold:   first.Threshold,
+		Parts:       first.Parts,
+		Index:       first.To,
+		Value:       make([]byte, len(first.Value)),
+	}
+	for i, basis := range lagrangeCoefficients(xs, 0) {
+		mulAdd(out.Value, subs[i].Value, basis)
+	}
+
+	if err := out.validate(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func validateGF256(share *Share) error {
+	if share.Scheme != SchemeGF256 {
+		return ErrUnsupportedScheme
+	}
+	return share.validate()
+}
+
+func newSubShares(share *Share, threshold, parts int, to []int) []*SubShare {
+	subs := make([]*SubShare, len(to))
+	for i, idx := range to {
+		subs[i] = &SubShare{
+			ID:           share.ID,
+			Fingerprint:  share.Fingerprint,
+			OldThreshold: share.Threshold,
+			Threshold:    threshold,
+			Parts:        parts,
+			From:         share.Index,
+			To:           idx,
+			Value:        make([]byte, len(share.Value)),
+		}
+	}
+	return subs
+}
+
+// checkHolders makes sure the holder list is a valid set of x coordinates
+// that includes the share itself and reaches the threshold
+func checkHolders(share *Share, holders []int) error {
+	if len(holders) < share.Threshold {
+		return fmt.Errorf("need %d holders, got %d", share.Threshold, len(holders))
+	}
+
+	self := false
+	seen := map[int]bool{}
+	for _, h := range holders {
+		if h < 1 || h > 255 || seen[h] {
+			return fmt.Errorf("invalid holder index %d", h)
+		}
+		seen[h] = true
+		self = self || h == share.Index
+	}
+	if !self {
+		return fmt.Errorf("holders must include the share index %d", share.Index)
+	}
+
+	return nil
+}
+
+// checkSubShares checks that the sub-shares are all addressed to `to`,
+// belong to the same round and come from distinct holders. It returns the
+// sorted dealer indices.
+func checkSubShares(subs []*SubShare, to, size int) ([]int, error) {
+	if len(subs) == 0 {
+		return nil, ErrSubShareMismatch
+	}
+
+	first := subs[0]
+	from := make([]int, 0, len(subs))
+	seen := map[int]bool{}
+	for _, sub := range subs {
+		if sub.To != to || len(sub.Value) != size || size == 0 {
+			return nil, fmt.Errorf("%w: sub-share from %d", ErrSubShareMismatch, sub.From)
+		}
+		if sub.ID != first.ID || sub.Fingerprint != first.Fingerprint || sub.OldThreshold != first.OldThreshold ||
+			sub.Threshold != first.Threshold || sub.Parts != first.Parts {
+			return nil, ErrSubShareMismatch
+		}
+		if sub.From < 1 || sub.From > 255 || seen[sub.From] {
+			return nil, fmt.Errorf("%w: duplicate or invalid dealer %d", ErrSubShareMismatch, sub.From)
+		}
+		seen[sub.From] = true
+		from = append(from, sub.From)
+	}
+
+	slices.Sort(from)
+	return from, nil
+}
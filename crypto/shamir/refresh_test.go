@@ -0,0 +1,214 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// route delivers every sub-share to its recipient
+func route(subs [][]*SubShare) map[int][]*SubShare {
+	inbox := map[int][]*SubShare{}
+	for _, dealt := range subs {
+		for _, sub := range dealt {
+			inbox[sub.To] = append(inbox[sub.To], sub)
+		}
+	}
+	return inbox
+}
+
+func indices(shares []*Share) []int {
+	out := make([]int, len(shares))
+	for i, s := range shares {
+		out[i] = s.Index
+	}
+	return out
+}
+
+func TestRefresh(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	old, err := SplitShares(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the fifth custodian leaves, the others refresh among themselves
+	holders := indices(old[:4])
+
+	var dealt [][]*SubShare
+	for _, s := range old[:4] {
+		subs, err := DealRefresh(s, holders)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		dealt = append(dealt, subs)
+	}
+	inbox := route(dealt)
+
+	var refreshed []*Share
+	for _, s := range old[:4] {
+		n, err := ApplyRefresh(s, holders, inbox[s.Index])
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if n.Index != s.Index || n.ID != s.ID || bytes.Equal(n.Value, s.Value) {
+			t.Fatalf("bad: %+v", n)
+		}
+		refreshed = append(refreshed, n)
+	}
+
+	for _, subset := range [][]*Share{refreshed[:3], refreshed[1:], refreshed} {
+		out, err := CombineShares(subset)
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %q %v", out, err)
+		}
+	}
+
+	// old shares, including the one of the custodian who left, no longer
+	// combine with the refreshed ones
+	for _, mixed := range [][]*Share{
+		{refreshed[0], refreshed[1], old[4]},
+		{refreshed[0], old[1], old[2]},
+	} {
+		if _, err := CombineShares(mixed); !errors.Is(err, ErrFingerprintMismatch) {
+			t.Fatalf("expect ErrFingerprintMismatch, got %v", err)
+		}
+	}
+}
+
+func TestRefresh_invalid(t *testing.T) {
+	old, err := SplitShares([]byte("test"), 4, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	holders := indices(old)
+
+	if _, err := DealRefresh(old[0], holders[:2]); err == nil {
+		t.Fatalf("expect error")
+	}
+	if _, err := DealRefresh(old[0], holders[1:]); err == nil {
+		t.Fatalf("expect error")
+	}
+	if _, err := DealRefresh(old[0], append(holders, holders[1])); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	var dealt [][]*SubShare
+	for _, s := range old {
+		subs, _ := DealRefresh(s, holders)
+		dealt = append(dealt, subs)
+	}
+	inbox := route(dealt)
+
+	// a missing dealer
+	if _, err := ApplyRefresh(old[0], holders, inbox[old[0].Index][1:]); !errors.Is(err, ErrSubShareMismatch) {
+		t.Fatalf("expect ErrSubShareMismatch, got %v", err)
+	}
+
+	// sub-shares addressed to somebody else
+	if _, err := ApplyRefresh(old[0], holders, inbox[old[1].Index]); !errors.Is(err, ErrSubShareMismatch) {
+		t.Fatalf("expect ErrSubShareMismatch, got %v", err)
+	}
+
+	// sub-shares of another secret
+	other, _ := SplitShares([]byte("tset"), 4, 3)
+	if _, err := ApplyRefresh(other[0], indices(other), inbox[old[0].Index]); err == nil {
+		t.Fatalf("expect error")
+	}
+}
+
+func TestReshare(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	old, err := SplitShares(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	for _, tc := range []struct {
+		dealers          []*Share
+		threshold, parts int
+	}{
+		{old[:3], 2, 3},
+		{old[1:], 4, 7},
+		{[]*Share{old[4], old[0], old[2]}, 3, 5},
+	} {
+		var dealt [][]*SubShare
+		for _, s := range tc.dealers {
+			subs, err := DealReshare(s, tc.threshold, tc.parts)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			dealt = append(dealt, subs)
+		}
+		inbox := route(dealt)
+
+		if len(inbox) != tc.parts {
+			t.Fatalf("bad: %d recipients", len(inbox))
+		}
+
+		var fresh []*Share
+		for to := 1; to <= tc.parts; to++ {
+			s, err := ApplyReshare(inbox[to])
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if s.Threshold != tc.threshold || s.Parts != tc.parts || s.Index != to {
+				t.Fatalf("bad: %+v", s)
+			}
+			fresh = append(fresh, s)
+		}
+
+		out, err := CombineShares(fresh[len(fresh)-tc.threshold:])
+		if err != nil || !bytes.Equal(out, secret) {
+			t.Fatalf("%d/%d: bad: %q %v", tc.threshold, tc.parts, out, err)
+		}
+
+		if _, err := CombineShares(fresh[:tc.threshold-1]); err == nil {
+			t.Fatalf("expect error")
+		}
+	}
+}
+
+func TestReshare_invalid(t *testing.T) {
+	old, err := SplitShares([]byte("test"), 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := DealReshare(old[0], 3, 2); err == nil {
+		t.Fatalf("expect error")
+	}
+	if _, err := DealReshare(old[0], 1, 3); err == nil {
+		t.Fatalf("expect error")
+	}
+	if _, err := DealReshare(old[0], 2, 256); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	// fewer dealers than the old threshold
+	var dealt [][]*SubShare
+	for _, s := range old[:2] {
+		subs, _ := DealReshare(s, 2, 3)
+		dealt = append(dealt, subs)
+	}
+	inbox := route(dealt)
+	if _, err := ApplyReshare(inbox[1]); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	// the same dealer twice
+	if _, err := ApplyReshare([]*SubShare{inbox[1][0], inbox[1][0], inbox[1][1]}); !errors.Is(err, ErrSubShareMismatch) {
+		t.Fatalf("expect ErrSubShareMismatch, got %v", err)
+	}
+
+	// sub-shares for different recipients
+	if _, err := ApplyReshare([]*SubShare{inbox[1][0], inbox[2][1]}); !errors.Is(err, ErrSubShareMismatch) {
+		t.Fatalf("expect ErrSubShareMismatch, got %v", err)
+	}
+
+	if _, err := ApplyReshare(nil); !errors.Is(err, ErrSubShareMismatch) {
+		t.Fatalf("expect ErrSubShareMismatch, got %v", err)
+	}
+}
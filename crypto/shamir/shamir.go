@@ -3,9 +3,9 @@ package shamir
 import (
 	"crypto/rand"
 	"crypto/subtle"
+	"encoding/binary"
 	"fmt"
-	mathrand "math/rand"
-	"time"
+	"io"
 )
 
 const (
@@ -21,7 +21,9 @@ type polynomial struct {
 }
 
 // makePolynomial constructs a random polynomial of the given
-// degree but with the provided intercept value.
+// degree but with the provided intercept value. split evaluates all
+// bytes at once and no longer uses it; it remains the reference
+// for the polynomial tests.
 func makePolynomial(intercept, degree uint8) (polynomial, error) {
 	// Create a wrapper
 	p := polynomial{
@@ -59,23 +61,31 @@ func (p *polynomial) evaluate(x uint8) uint8 {
 // interpolatePolynomial takes N sample points and returns
 // the value at a given x using a lagrange interpolation.
 func interpolatePolynomial(x_samples, y_samples []uint8, x uint8) uint8 {
-	limit := len(x_samples)
-	var result, basis uint8
-	for i := 0; i < limit; i++ {
-		basis = 1
-		for j := 0; j < limit; j++ {
+	var result uint8
+	for i, basis := range lagrangeCoefficients(x_samples, x) {
+		result = add(result, mult(y_samples[i], basis))
+	}
+	return result
+}
+
+// lagrangeCoefficients returns the Lagrange basis polynomial of each
+// sample evaluated at x. They only depend on the x coordinates, so
+// callers compute them once and reuse them for every byte of a secret.
+func lagrangeCoefficients(x_samples []uint8, x uint8) []uint8 {
+	out := make([]uint8, len(x_samples))
+	for i := range x_samples {
+		basis := uint8(1)
+		for j := range x_samples {
 			if i == j {
 				continue
 			}
 			num := add(x, x_samples[j])
 			denom := add(x_samples[i], x_samples[j])
-			term := div(num, denom)
-			basis = mult(basis, term)
+			basis = mult(basis, div(num, denom))
 		}
-		group := mult(y_samples[i], basis)
-		result = add(result, group)
+		out[i] = basis
 	}
-	return result
+	return out
 }
 
 // div divides two numbers in GF(2^8)
@@ -128,6 +138,35 @@ func add(a, b uint8) uint8 {
 	return a ^ b
 }
 
+// mulAdd sets dst[i] ^= c * src[i] for every i < len(dst). Eight bytes are
+// processed at a time as lanes of a uint64: c * x^k is precomputed in every
+// lane and selected with a mask built from bit k of each source byte, so
+// there are no lookup tables and no branches on the data.
+func mulAdd(dst, src []byte, c uint8) {
+	const lanes = 0x0101010101010101
+
+	var ck [8]uint64
+	for k := range ck {
+		ck[k] = uint64(c) * lanes
+		c = mult(c, 2)
+	}
+
+	n := len(dst) &^ 7
+	for i := 0; i < n; i += 8 {
+		s := binary.LittleEndian.Uint64(src[i:])
+		var r uint64
+		for k := range ck {
+			r ^= (s >> k & lanes) * 0xff & ck[k]
+		}
+		binary.LittleEndian.PutUint64(dst[i:], binary.LittleEndian.Uint64(dst[i:])^r)
+	}
+
+	c = uint8(ck[0])
+	for i := n; i < len(dst); i++ {
+		dst[i] ^= mult(c, src[i])
+	}
+}
+
 // Split takes an arbitrarily long secret and generates a `parts`
 // number of shares, `threshold` of which are required to reconstruct
 // the secret. The parts and threshold must be at least 2, and less
@@ -152,35 +191,58 @@ func Split(secret []byte, parts, threshold int) ([][]byte, error) {
 	}
 
 	// Generate random list of x coordinates
-	mathrand.Seed(time.Now().UnixNano())
-	xCoordinates := mathrand.Perm(255)
+	xCoordinates, err := randomIndices(rand.Reader, parts, 255)
+	if err != nil {
+		return nil, err
+	}
+
+	return split(secret, xCoordinates, threshold, rand.Reader)
+}
 
+// split shares the secret at the given x coordinates, which must be
+// distinct and in [1, 255].
+func split(secret []byte, xCoordinates []int, threshold int, random io.Reader) ([][]byte, error) {
 	// Allocate the output array, initialize the final byte
 	// of the output with the offset. The representation of each
 	// output is {y1, y2, .., yN, x}.
-	out := make([][]byte, parts)
+	out := make([][]byte, len(xCoordinates))
 	for idx := range out {
 		out[idx] = make([]byte, len(secret)+1)
-		out[idx][len(secret)] = uint8(xCoordinates[idx]) + 1
+		out[idx][len(secret)] = uint8(xCoordinates[idx])
 	}
 
-	// Construct a random polynomial for each byte of the secret.
-	// Because we are using a field of size 256, we can only represent
-	// a single byte as the intercept of the polynomial, so we must
-	// use a new polynomial for each byte.
-	for idx, val := range secret {
-		p, err := makePolynomial(val, uint8(threshold-1))
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+	// Every byte of the secret still gets its own random polynomial, but
+	// the polynomials are stored by coefficient: coefficients[k-1] holds
+	// the k-th coefficient for a block of bytes. Each share is then
+	// y = secret + x * a_1 + x^2 * a_2 + ..., evaluated block-wise.
+	const blockSize = 4096
+	coefficients := make([][]byte, threshold-1)
+	for k := range coefficients {
+		coefficients[k] = make([]byte, blockSize)
+	}
+	defer func() {
+		for _, a := range coefficients {
+			clear(a)
 		}
+	}()
 
-		// Generate a `parts` number of (x,y) pairs
-		// We cheat by encoding the x value once as the final index,
-		// so that it only needs to be stored once.
-		for i := 0; i < parts; i++ {
-			x := uint8(xCoordinates[i]) + 1
-			y := p.evaluate(x)
-			out[i][idx] = y
+	for start := 0; start < len(secret); start += blockSize {
+		block := secret[start:min(start+blockSize, len(secret))]
+		for _, a := range coefficients {
+			if _, err := io.ReadFull(random, a[:len(block)]); err != nil {
+				return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+			}
+		}
+
+		for i, x := range xCoordinates {
+			y := out[i][start : start+len(block)]
+			copy(y, block)
+
+			power := uint8(1)
+			for _, a := range coefficients {
+				power = mult(power, uint8(x))
+				mulAdd(y, a, power)
+			}
 		}
 	}
 
@@ -212,7 +274,6 @@ func Combine(parts [][]byte) ([]byte, error) {
 
 	// Buffer to store the samples
 	x_samples := make([]uint8, len(parts))
-	y_samples := make([]uint8, len(parts))
 
 	// Set the x value for each sample and ensure no x_sample values are the same,
 	// otherwise div() can be unhappy
@@ -226,18 +287,10 @@ func Combine(parts [][]byte) ([]byte, error) {
 		x_samples[i] = samp
 	}
 
-	// Reconstruct each byte
-	for idx := range secret {
-		// Set the y value for each sample
-		for i, part := range parts {
-			y_samples[i] = part[idx]
-		}
-
-		// Interpolate the polynomial and compute the value at 0
-		val := interpolatePolynomial(x_samples, y_samples, 0)
-
-		// Evaluate the 0th value to get the intercept
-		secret[idx] = val
+	// The secret is the value of the polynomial at 0, a weighted sum of the
+	// parts with weights that are the same for every byte
+	for i, basis := range lagrangeCoefficients(x_samples, 0) {
+		mulAdd(secret, parts[i], basis)
 	}
 	return secret, nil
 }
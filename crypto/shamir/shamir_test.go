@@ -2,6 +2,7 @@ package shamir
 
 import (
 	"bytes"
+	"math/rand/v2"
 	"testing"
 )
 
@@ -196,3 +197,123 @@ func TestInterpolate_Rand(t *testing.T) {
 		}
 	}
 }
+
+func TestMulAdd(t *testing.T) {
+	// lengths around the 8 byte word size exercise the tail
+	for _, size := range []int{1, 7, 8, 9, 16, 31} {
+		src := make([]byte, size)
+		for i := range src {
+			src[i] = uint8(i*37 + 11)
+		}
+
+		for c := 0; c < 256; c++ {
+			dst := bytes.Repeat([]byte{0x5a}, size)
+			mulAdd(dst, src, uint8(c))
+			for i := range dst {
+				if exp := add(0x5a, mult(uint8(c), src[i])); dst[i] != exp {
+					t.Fatalf("bad: size %d c %d index %d: expect %v, got %v", size, c, i, exp, dst[i])
+				}
+			}
+		}
+	}
+}
+
+func TestLagrangeCoefficients(t *testing.T) {
+	xs := []uint8{1, 2, 3}
+	for x := 0; x < 256; x++ {
+		coefficients := lagrangeCoefficients(xs, uint8(x))
+
+		// a basis polynomial is 1 at its own sample and 0 at the others
+		for i, xi := range xs {
+			if uint8(x) != xi {
+				continue
+			}
+			for j, c := range coefficients {
+				exp := uint8(0)
+				if i == j {
+					exp = 1
+				}
+				if c != exp {
+					t.Fatalf("bad: x %d: %v", x, coefficients)
+				}
+			}
+		}
+
+		// the basis polynomials sum to 1 everywhere
+		var sum uint8
+		for _, c := range coefficients {
+			sum = add(sum, c)
+		}
+		if sum != 1 {
+			t.Fatalf("bad: x %d: %v", x, coefficients)
+		}
+	}
+}
+
+func FuzzSplitCombine(f *testing.F) {
+	f.Add([]byte("test"), uint8(5), uint8(3), uint64(0))
+	f.Add([]byte{0}, uint8(2), uint8(2), uint64(1))
+	f.Add(bytes.Repeat([]byte{0xff}, 4097), uint8(20), uint8(10), uint64(42))
+
+	f.Fuzz(func(t *testing.T, secret []byte, parts, threshold uint8, seed uint64) {
+		if len(secret) == 0 {
+			return
+		}
+
+		// 2 <= threshold <= parts <= 20
+		n := 2 + int(parts)%19
+		k := 2 + int(threshold)%(n-1)
+
+		out, err := Split(secret, n, k)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// any subset of at least threshold parts, in any order
+		r := rand.New(rand.NewPCG(seed, 0))
+		r.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+		subset := out[:k+r.IntN(n-k+1)]
+
+		recomb, err := Combine(subset)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !bytes.Equal(recomb, secret) {
+			t.Fatalf("bad: %d of %d/%d: expect %x, got %x", len(subset), k, n, secret, recomb)
+		}
+	})
+}
+
+func benchmarkSplit(b *testing.B, size, parts, threshold int) {
+	secret := make([]byte, size)
+	b.SetBytes(int64(size))
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Split(secret, parts, threshold); err != nil {
+			b.Fatalf("err: %v", err)
+		}
+	}
+}
+
+func benchmarkCombine(b *testing.B, size, parts, threshold int) {
+	out, err := Split(make([]byte, size), parts, threshold)
+	if err != nil {
+		b.Fatalf("err: %v", err)
+	}
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Combine(out[:threshold]); err != nil {
+			b.Fatalf("err: %v", err)
+		}
+	}
+}
+
+func BenchmarkSplit_32B_3of5(b *testing.B)    { benchmarkSplit(b, 32, 5, 3) }
+func BenchmarkSplit_1MiB_3of5(b *testing.B)   { benchmarkSplit(b, 1<<20, 5, 3) }
+func BenchmarkSplit_1MiB_10of20(b *testing.B) { benchmarkSplit(b, 1<<20, 20, 10) }
+
+func BenchmarkCombine_32B_3of5(b *testing.B)    { benchmarkCombine(b, 32, 5, 3) }
+func BenchmarkCombine_1MiB_3of5(b *testing.B)   { benchmarkCombine(b, 1<<20, 5, 3) }
+func BenchmarkCombine_1MiB_10of20(b *testing.B) { benchmarkCombine(b, 1<<20, 20, 10) }
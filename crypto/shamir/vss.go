@@ -0,0 +1,297 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Verifiable secret sharing (Pedersen, 1991) over the prime-order
+// subgroup of the RFC 7919 ffdhe2048 group.
+//
+// The dealer publishes a Commitment C_j = g^a_j * h^b_j for the
+// coefficients of two random polynomials f (with f(0) = secret) and r.
+// Share i is (f(i), r(i)) and can be checked on its own against the
+// commitment. Unlike Feldman's scheme the commitment is perfectly
+// hiding, so it can be published even for low entropy secrets.
+//
+// The commitment must reach every holder over an authenticated channel,
+// otherwise a malicious dealer can hand out different commitments.
+
+const (
+	// MaxVerifiableSecret is the longest secret SplitVerifiable accepts.
+	// Any 255 byte value is smaller than the 2047 bit group order.
+	MaxVerifiableSecret = 255
+
+	// pedersenDST separates the derivation of h from any other use of SHA-256
+	pedersenDST = "tools/crypto/shamir pedersen generator v1"
+)
+
+var (
+	ErrInvalidShare      = errors.New("share does not match the commitment")
+	ErrInvalidCommitment = errors.New("invalid commitment")
+)
+
+var (
+	// groupP is the ffdhe2048 safe prime p = 2q + 1
+	groupP, _ = new(big.Int).SetString(
+		"FFFFFFFFFFFFFFFFADF85458A2BB4A9AAFDC5620273D3CF1D8B9C583CE2D3695"+
+			"A9E13641146433FBCC939DCE249B3EF97D2FE363630C75D8F681B202AEC4617A"+
+			"D3DF1ED5D5FD65612433F51F5F066ED0856365553DED1AF3B557135E7F57C935"+
+			"984F0C70E0E68B77E2A689DAF3EFE8721DF158A136ADE73530ACCA4F483A797A"+
+			"BC0AB182B324FB61D108A94BB2C8E3FBB96ADAB760D7F4681D4F42A3DE394DF4"+
+			"AE56EDE76372BB190B07A7C8EE0A6D709E02FCE1CDF7E2ECC03404CD28342F61"+
+			"9172FE9CE98583FF8E4F1232EEF28183C3FE3B1B4C6FAD733BB5FCBC2EC22005"+
+			"C58EF1837D1683B2C6F34A26C1B2EFFA886B423861285C97FFFFFFFFFFFFFFFF", 16)
+
+	// groupQ is the prime order of the subgroup generated by groupG
+	groupQ = new(big.Int).Rsh(groupP, 1)
+
+	groupG = big.NewInt(2)
+
+	// groupH is a second generator whose discrete log to groupG is unknown
+	groupH = deriveGenerator(pedersenDST)
+)
+
+// deriveGenerator hashes the label to an integer modulo p and squares it,
+// which always lands in the subgroup of quadratic residues of order q.
+func deriveGenerator(label string) *big.Int {
+	// 64 extra bytes keep the bias of the modular reduction negligible
+	buf := make([]byte, 0, 320)
+	for ctr := uint32(0); len(buf) < cap(buf); ctr++ {
+		h := sha256.New()
+		h.Write([]byte(label))
+		h.Write(binary.BigEndian.AppendUint32(nil, ctr))
+		buf = h.Sum(buf)
+	}
+
+	x := new(big.Int).SetBytes(buf)
+	x.Mod(x, groupP)
+	return x.Exp(x, big.NewInt(2), groupP)
+}
+
+// Commitment is the public output of SplitVerifiable. Points[j] commits to
+// the j-th coefficient of the sharing polynomial.
+type Commitment struct {
+	// Length is the byte length of the secret
+	Length int
+	Points []*big.Int
+}
+
+// Threshold returns the number of shares needed to recover the secret
+func (c *Commitment) Threshold() int {
+	return len(c.Points)
+}
+
+// VerifiableShare is one share of a SplitVerifiable secret
+type VerifiableShare struct {
+	// Index is the x coordinate of the share, starting at 1
+	Index int
+	Value *big.Int
+	Blind *big.Int
+}
+
+// InvalidSharesError lists the indices of the shares that failed verification
+type InvalidSharesError struct {
+	Indices []int
+}
+
+func (e *InvalidSharesError) Error() string {
+	return fmt.Sprintf("invalid shares: %v", e.Indices)
+}
+
+func (e *InvalidSharesError) Unwrap() error {
+	return ErrInvalidShare
+}
+
+// SplitVerifiable splits a secret of at most MaxVerifiableSecret bytes into
+// `parts` shares with indices 1..parts, `threshold` of which are required to
+// reconstruct it. Every share can be checked against the returned commitment.
+func SplitVerifiable(secret []byte, parts, threshold int) (*Commitment, []*VerifiableShare, error) {
+	if parts < threshold {
+		return nil, nil, fmt.Errorf("parts cannot be less than threshold")
+	}
+	if parts > 255 {
+		return nil, nil, fmt.Errorf("parts cannot exceed 255")
+	}
+	if threshold < 2 {
+		return nil, nil, fmt.Errorf("threshold must be at least 2")
+	}
+	if len(secret) == 0 {
+		return nil, nil, fmt.Errorf("cannot split an empty secret")
+	}
+	if len(secret) > MaxVerifiableSecret {
+		return nil, nil, fmt.Errorf("secret cannot exceed %d bytes", MaxVerifiableSecret)
+	}
+
+	// f(0) is the secret, r(0) is random
+	f := make([]*big.Int, threshold)
+	r := make([]*big.Int, threshold)
+	f[0] = new(big.Int).SetBytes(secret)
+	for j := range f {
+		var err error
+		if j > 0 {
+			if f[j], err = rand.Int(rand.Reader, groupQ); err != nil {
+				return nil, nil, err
+			}
+		}
+		if r[j], err = rand.Int(rand.Reader, groupQ); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c := &Commitment{Length: len(secret), Points: make([]*big.Int, threshold)}
+	for j := range f {
+		c.Points[j] = pedersen(f[j], r[j])
+	}
+
+	shares := make([]*VerifiableShare, parts)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		shares[i] = &VerifiableShare{
+			Index: i + 1,
+			Value: evaluateModQ(f, x),
+			Blind: evaluateModQ(r, x),
+		}
+	}
+
+	return c, shares, nil
+}
+
+// Verify checks a single share against the commitment
+func (c *Commitment) Verify(share *VerifiableShare) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	return c.verify(share)
+}
+
+func (c *Commitment) verify(share *VerifiableShare) error {
+	if share == nil || share.Index < 1 || !inRange(share.Value, groupQ) || !inRange(share.Blind, groupQ) {
+		return ErrInvalidShare
+	}
+
+	// prod C_j^(i^j), evaluated with Horner's method in the exponent
+	x := big.NewInt(int64(share.Index))
+	expect := new(big.Int).Set(c.Points[len(c.Points)-1])
+	for j := len(c.Points) - 2; j >= 0; j-- {
+		expect.Exp(expect, x, groupP)
+		expect.Mul(expect, c.Points[j])
+		expect.Mod(expect, groupP)
+	}
+
+	if pedersen(share.Value, share.Blind).Cmp(expect) != 0 {
+		return ErrInvalidShare
+	}
+
+	return nil
+}
+
+// CombineVerifiable verifies every share against the commitment and
+// reconstructs the secret. If any share is invalid nothing is reconstructed
+// and an *InvalidSharesError listing the bad indices is returned, so the
+// caller can drop them and retry with the remaining shares.
+func CombineVerifiable(c *Commitment, shares []*VerifiableShare) ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	var bad []int
+	for _, share := range shares {
+		if share == nil {
+			return nil, ErrInvalidShare
+		}
+		if seen[share.Index] {
+			return nil, fmt.Errorf("duplicate part detected")
+		}
+		seen[share.Index] = true
+
+		if c.verify(share) != nil {
+			bad = append(bad, share.Index)
+		}
+	}
+	if len(bad) > 0 {
+		sort.Ints(bad)
+		return nil, &InvalidSharesError{Indices: bad}
+	}
+
+	if len(shares) < c.Threshold() {
+		return nil, fmt.Errorf("need %d shares, got %d", c.Threshold(), len(shares))
+	}
+
+	// Any threshold valid shares lie on the committed polynomial
+	shares = shares[:c.Threshold()]
+
+	secret := new(big.Int)
+	for i, si := range shares {
+		num, den := big.NewInt(1), big.NewInt(1)
+		xi := big.NewInt(int64(si.Index))
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj := big.NewInt(int64(sj.Index))
+			num.Mul(num, xj)
+			den.Mul(den, new(big.Int).Sub(xj, xi))
+		}
+
+		// basis_i(0) = prod x_j / (x_j - x_i)
+		den.Mod(den, groupQ)
+		basis := num.Mul(num, den.ModInverse(den, groupQ))
+		secret.Add(secret, basis.Mul(basis, si.Value))
+	}
+	secret.Mod(secret, groupQ)
+
+	if secret.BitLen() > c.Length*8 {
+		return nil, ErrInvalidCommitment
+	}
+
+	return secret.FillBytes(make([]byte, c.Length)), nil
+}
+
+func (c *Commitment) validate() error {
+	if c == nil || len(c.Points) < 2 || c.Length < 1 || c.Length > MaxVerifiableSecret {
+		return ErrInvalidCommitment
+	}
+
+	for _, p := range c.Points {
+		// Every point must be a non-identity element of the order q subgroup
+		if !inRange(p, groupP) || p.Cmp(big.NewInt(1)) == 0 {
+			return ErrInvalidCommitment
+		}
+		if new(big.Int).Exp(p, groupQ, groupP).Cmp(big.NewInt(1)) != 0 {
+			return ErrInvalidCommitment
+		}
+	}
+
+	return nil
+}
+
+// pedersen returns g^a * h^b mod p
+func pedersen(a, b *big.Int) *big.Int {
+	ga := new(big.Int).Exp(groupG, a, groupP)
+	hb := new(big.Int).Exp(groupH, b, groupP)
+	return ga.Mod(ga.Mul(ga, hb), groupP)
+}
+
+// evaluateModQ evaluates the polynomial with the given coefficients at x
+func evaluateModQ(coefficients []*big.Int, x *big.Int) *big.Int {
+	out := new(big.Int).Set(coefficients[len(coefficients)-1])
+	for j := len(coefficients) - 2; j >= 0; j-- {
+		out.Mul(out, x)
+		out.Add(out, coefficients[j])
+		out.Mod(out, groupQ)
+	}
+	return out
+}
+
+// inRange reports whether 0 <= v < limit
+func inRange(v, limit *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(limit) < 0
+}
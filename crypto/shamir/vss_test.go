@@ -0,0 +1,148 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestGroupParameters(t *testing.T) {
+	if !groupP.ProbablyPrime(20) || !groupQ.ProbablyPrime(20) {
+		t.Fatalf("bad: p and q must be prime")
+	}
+
+	one := big.NewInt(1)
+	for _, g := range []*big.Int{groupG, groupH} {
+		if g.Cmp(one) == 0 || new(big.Int).Exp(g, groupQ, groupP).Cmp(one) != 0 {
+			t.Fatalf("bad generator: %x", g)
+		}
+	}
+}
+
+func TestSplitVerifiable(t *testing.T) {
+	secret := []byte("\x00\x00leading zeros are kept")
+
+	c, shares, err := SplitVerifiable(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if c.Threshold() != 3 || len(shares) != 5 {
+		t.Fatalf("bad: %d %d", c.Threshold(), len(shares))
+	}
+
+	for _, share := range shares {
+		if err := c.Verify(share); err != nil {
+			t.Fatalf("share %d: %v", share.Index, err)
+		}
+	}
+
+	for _, subset := range [][]*VerifiableShare{
+		shares[:3],
+		shares[2:],
+		{shares[4], shares[0], shares[2]},
+		shares,
+	} {
+		out, err := CombineVerifiable(c, subset)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !bytes.Equal(out, secret) {
+			t.Fatalf("bad: %q", out)
+		}
+	}
+
+	if _, err := CombineVerifiable(c, shares[:2]); err == nil {
+		t.Fatalf("expect error")
+	}
+	if _, err := CombineVerifiable(c, []*VerifiableShare{shares[0], shares[0], shares[1]}); err == nil {
+		t.Fatalf("expect error")
+	}
+}
+
+func TestSplitVerifiable_invalid(t *testing.T) {
+	secret := []byte("test")
+
+	for _, tc := range []struct {
+		secret           []byte
+		parts, threshold int
+	}{
+		{secret, 2, 3},
+		{secret, 256, 3},
+		{secret, 10, 1},
+		{nil, 3, 2},
+		{make([]byte, MaxVerifiableSecret+1), 3, 2},
+	} {
+		if _, _, err := SplitVerifiable(tc.secret, tc.parts, tc.threshold); err == nil {
+			t.Fatalf("%d/%d: expect error", tc.threshold, tc.parts)
+		}
+	}
+
+	if _, _, err := SplitVerifiable(bytes.Repeat([]byte{0xff}, MaxVerifiableSecret), 3, 2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestCombineVerifiable_badShares(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	c, shares, err := SplitVerifiable(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// share 2 corrupted, share 4 replaced by a share of another secret
+	corrupted := *shares[1]
+	corrupted.Value = new(big.Int).Add(corrupted.Value, big.NewInt(1))
+
+	_, other, _ := SplitVerifiable([]byte("Tr0ub4dor&3"), 5, 3)
+
+	input := []*VerifiableShare{shares[0], &corrupted, shares[2], other[3], shares[4]}
+	if err := c.Verify(&corrupted); !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("expect ErrInvalidShare, got %v", err)
+	}
+
+	_, err = CombineVerifiable(c, input)
+	var invalid *InvalidSharesError
+	if !errors.As(err, &invalid) || !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("expect InvalidSharesError, got %v", err)
+	}
+	if !reflect.DeepEqual(invalid.Indices, []int{2, 4}) {
+		t.Fatalf("bad: %v", invalid.Indices)
+	}
+
+	// the remaining shares still recover the secret
+	out, err := CombineVerifiable(c, []*VerifiableShare{shares[0], shares[2], shares[4]})
+	if err != nil || !bytes.Equal(out, secret) {
+		t.Fatalf("bad: %q %v", out, err)
+	}
+
+	// a share from another sharing does not verify even with a valid index
+	if err := c.Verify(other[0]); !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("expect ErrInvalidShare, got %v", err)
+	}
+}
+
+func TestCommitment_invalid(t *testing.T) {
+	c, shares, err := SplitVerifiable([]byte("test"), 3, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// p-1 has order 2, not q
+	bad := &Commitment{Length: c.Length, Points: []*big.Int{c.Points[0], new(big.Int).Sub(groupP, big.NewInt(1))}}
+	if err := bad.Verify(shares[0]); !errors.Is(err, ErrInvalidCommitment) {
+		t.Fatalf("expect ErrInvalidCommitment, got %v", err)
+	}
+	if _, err := CombineVerifiable(&Commitment{Length: 4, Points: c.Points[:1]}, shares); !errors.Is(err, ErrInvalidCommitment) {
+		t.Fatalf("expect ErrInvalidCommitment, got %v", err)
+	}
+
+	if err := c.Verify(&VerifiableShare{Index: 0, Value: shares[0].Value, Blind: shares[0].Blind}); !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("expect ErrInvalidShare, got %v", err)
+	}
+	if err := c.Verify(&VerifiableShare{Index: 1, Value: groupQ, Blind: shares[0].Blind}); !errors.Is(err, ErrInvalidShare) {
+		t.Fatalf("expect ErrInvalidShare, got %v", err)
+	}
+}
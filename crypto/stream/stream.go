@@ -0,0 +1,317 @@
+// Package stream 实现 STREAM 分段认证加密（与 age 的格式类似）：
+// 明文被切分为固定大小的分段，每段独立使用 AEAD 加密，
+// nonce 由分段序号和“最后一段”标记构成，因此分段被重排、
+// 删除或在分段边界处截断都会被发现。
+//
+// 密文格式：
+//
+//	header: magic(4) || version(1) || chunkSize(4) || salt(16)
+//	chunks: AEAD(chunk_0) || AEAD(chunk_1) || ... || AEAD(chunk_last)
+//
+// 每个流的分段密钥由 HKDF(key, salt, header) 派生，
+// header 被篡改时派生出的密钥不同，第一个分段就会认证失败
+package stream
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// AEADFunc 根据 32 字节密钥构造 AEAD，例如 chacha20poly1305.New
+type AEADFunc func(key []byte) (cipher.AEAD, error)
+
+const (
+	DefaultChunkSize = 64 * 1024
+
+	minChunkSize = 1024
+	maxChunkSize = 16 * 1024 * 1024
+
+	version    = 0x01
+	saltSize   = 16
+	headerSize = 4 + 1 + 4 + saltSize
+	keySize    = 32
+)
+
+var magic = []byte("GTS\x00")
+
+var (
+	ErrAuthFailed         = errors.New("stream: chunk authentication failed")
+	ErrTruncated          = errors.New("stream: stream truncated")
+	ErrInvalidHeader      = errors.New("stream: invalid header")
+	ErrUnsupportedVersion = errors.New("stream: unsupported version")
+	ErrInvalidChunkSize   = errors.New("stream: invalid chunk size")
+	ErrClosed             = errors.New("stream: write to closed stream")
+)
+
+// Encrypt 将 src 加密写入 dst，使用默认分段大小
+func Encrypt(dst io.Writer, src io.Reader, key []byte, newAEAD AEADFunc) error {
+	w, err := NewWriter(dst, key, newAEAD)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// Decrypt 将 src 中的密文解密写入 dst。
+// 明文按分段边写边校验，返回错误时已写入 dst 的数据必须丢弃
+func Decrypt(dst io.Writer, src io.Reader, key []byte, newAEAD AEADFunc) error {
+	r, err := NewReader(src, key, newAEAD)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+type writer struct {
+	dst     io.Writer
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint64
+
+	buf []byte
+	out []byte
+
+	chunkSize int
+	closed    bool
+	err       error
+}
+
+// NewWriter 返回加密写入器，写入的明文加密后输出到 dst。
+// 调用方必须 Close，否则最后一个分段不会写出，解密端会报告截断
+func NewWriter(dst io.Writer, key []byte, newAEAD AEADFunc) (io.WriteCloser, error) {
+	return NewWriterSize(dst, key, newAEAD, DefaultChunkSize)
+}
+
+// NewWriterSize 与 NewWriter 相同，但可以指定明文分段大小
+func NewWriterSize(dst io.Writer, key []byte, newAEAD AEADFunc, chunkSize int) (io.WriteCloser, error) {
+	if chunkSize < minChunkSize || chunkSize > maxChunkSize {
+		return nil, ErrInvalidChunkSize
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[4] = version
+	binary.BigEndian.PutUint32(header[5:], uint32(chunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[9:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := streamAEAD(key, header, newAEAD)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dst.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		dst:       dst,
+		aead:      aead,
+		nonce:     make([]byte, aead.NonceSize()),
+		buf:       make([]byte, 0, chunkSize),
+		out:       make([]byte, 0, chunkSize+aead.Overhead()),
+		chunkSize: chunkSize,
+	}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	total := len(p)
+	for len(p) > 0 {
+		// 只有确认后面还有数据时才写出整段，最后一段留给 Close
+		if len(w.buf) == w.chunkSize {
+			if err := w.flush(false); err != nil {
+				return total - len(p), err
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):w.chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+	}
+
+	return total, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if w.err != nil {
+		return w.err
+	}
+
+	return w.flush(true)
+}
+
+func (w *writer) flush(last bool) error {
+	setNonce(w.nonce, w.counter, last)
+	w.out = w.aead.Seal(w.out[:0], w.nonce, w.buf, nil)
+
+	if _, err := w.dst.Write(w.out); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+type reader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint64
+
+	in      []byte
+	out     []byte
+	pending []byte
+
+	done bool
+	err  error
+}
+
+// NewReader 读取并校验流头部，返回解密读取器。
+// 每个分段在返回前都已认证，流在最后一段之前结束时返回 ErrTruncated
+func NewReader(src io.Reader, key []byte, newAEAD AEADFunc) (io.Reader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if string(header[:4]) != string(magic) {
+		return nil, ErrInvalidHeader
+	}
+	if header[4] != version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(header[5:]))
+	if chunkSize < minChunkSize || chunkSize > maxChunkSize {
+		return nil, ErrInvalidChunkSize
+	}
+
+	aead, err := streamAEAD(key, header, newAEAD)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{
+		src:   bufio.NewReader(src),
+		aead:  aead,
+		nonce: make([]byte, aead.NonceSize()),
+		in:    make([]byte, chunkSize+aead.Overhead()),
+		out:   make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+
+		r.pending, r.err = r.readChunk()
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *reader) readChunk() ([]byte, error) {
+	n, err := io.ReadFull(r.src, r.in)
+	switch {
+	case err == io.EOF:
+		// 上一个分段不是最后一段，但流已经结束
+		return nil, ErrTruncated
+	case err == io.ErrUnexpectedEOF:
+		// 不足一个完整分段，只能是最后一段
+	case err != nil:
+		return nil, err
+	}
+
+	last := n < len(r.in)
+	if !last {
+		if _, err := r.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if n < r.aead.Overhead() {
+		return nil, ErrTruncated
+	}
+
+	setNonce(r.nonce, r.counter, last)
+	out, err := r.aead.Open(r.out[:0], r.nonce, r.in[:n], nil)
+	if err != nil {
+		if last {
+			// 完整分段之后正好被截断：按非最后一段能通过认证
+			setNonce(r.nonce, r.counter, false)
+			if _, err := r.aead.Open(nil, r.nonce, r.in[:n], nil); err == nil {
+				return nil, ErrTruncated
+			}
+		}
+		return nil, ErrAuthFailed
+	}
+
+	r.counter++
+	r.done = last
+	return out, nil
+}
+
+// streamAEAD 为单个流派生独立的分段密钥，避免不同流之间的 nonce 重用
+func streamAEAD(key, header []byte, newAEAD AEADFunc) (cipher.AEAD, error) {
+	streamKey := make([]byte, keySize)
+	kdf := hkdf.New(sha256.New, key, header[9:], append([]byte("tools/crypto/stream"), header...))
+	if _, err := io.ReadFull(kdf, streamKey); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(streamKey)
+	if err != nil {
+		return nil, err
+	}
+	if aead.NonceSize() < 9 {
+		return nil, errors.New("stream: aead nonce too short")
+	}
+
+	return aead, nil
+}
+
+// setNonce 将分段序号（大端）写入 nonce 的末尾，最后一个字节标记最后一段
+func setNonce(nonce []byte, counter uint64, last bool) {
+	clear(nonce)
+	binary.BigEndian.PutUint64(nonce[len(nonce)-9:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+}
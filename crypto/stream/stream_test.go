@@ -0,0 +1,144 @@
+package stream
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func testKey() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
+
+func seal(t *testing.T, key, msg []byte, chunkSize int) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriterSize(&buf, key, chacha20poly1305.New, chunkSize)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Uneven writes exercise the chunk buffering
+	for len(msg) > 0 {
+		n := min(len(msg), 777)
+		if _, err := w.Write(msg[:n]); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		msg = msg[n:]
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestStream(t *testing.T) {
+	key := testKey()
+
+	for _, size := range []int{0, 1, 1023, 1024, 1025, 2048, 5000} {
+		msg := make([]byte, size)
+		rand.Read(msg)
+
+		sealed := seal(t, key, msg, 1024)
+
+		var out bytes.Buffer
+		if err := Decrypt(&out, bytes.NewReader(sealed), key, chacha20poly1305.New); err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		if !bytes.Equal(out.Bytes(), msg) {
+			t.Fatalf("size %d: bad output", size)
+		}
+	}
+}
+
+func TestStream_truncated(t *testing.T) {
+	key := testKey()
+	msg := make([]byte, 3000)
+	sealed := seal(t, key, msg, 1024)
+
+	chunk := 1024 + chacha20poly1305.Overhead
+
+	// Cut exactly after the first and second full chunks
+	for _, cut := range []int{headerSize + chunk, headerSize + 2*chunk} {
+		err := Decrypt(io.Discard, bytes.NewReader(sealed[:cut]), key, chacha20poly1305.New)
+		if !errors.Is(err, ErrTruncated) {
+			t.Fatalf("cut %d: expect ErrTruncated, got %v", cut, err)
+		}
+	}
+
+	// Cut in the middle of the last chunk
+	err := Decrypt(io.Discard, bytes.NewReader(sealed[:len(sealed)-5]), key, chacha20poly1305.New)
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expect ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestStream_reordered(t *testing.T) {
+	key := testKey()
+	msg := make([]byte, 3000)
+	sealed := seal(t, key, msg, 1024)
+
+	chunk := 1024 + chacha20poly1305.Overhead
+	first := sealed[headerSize : headerSize+chunk]
+	second := sealed[headerSize+chunk : headerSize+2*chunk]
+
+	var swapped []byte
+	swapped = append(swapped, sealed[:headerSize]...)
+	swapped = append(swapped, second...)
+	swapped = append(swapped, first...)
+	swapped = append(swapped, sealed[headerSize+2*chunk:]...)
+
+	err := Decrypt(io.Discard, bytes.NewReader(swapped), key, chacha20poly1305.New)
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expect ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestStream_tampered(t *testing.T) {
+	key := testKey()
+	sealed := seal(t, key, []byte("test"), 1024)
+
+	// Salt lives in the header; changing it derives a different key
+	tampered := append([]byte{}, sealed...)
+	tampered[headerSize-1] ^= 0x01
+	if err := Decrypt(io.Discard, bytes.NewReader(tampered), key, chacha20poly1305.New); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expect ErrAuthFailed, got %v", err)
+	}
+
+	tampered = append([]byte{}, sealed...)
+	tampered[4] = 0x7f
+	if err := Decrypt(io.Discard, bytes.NewReader(tampered), key, chacha20poly1305.New); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expect ErrUnsupportedVersion, got %v", err)
+	}
+
+	if err := Decrypt(io.Discard, bytes.NewReader(sealed), testKey(), chacha20poly1305.New); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expect ErrAuthFailed, got %v", err)
+	}
+
+	if err := Decrypt(io.Discard, bytes.NewReader(sealed[:3]), key, chacha20poly1305.New); !errors.Is(err, ErrInvalidHeader) {
+		t.Fatalf("expect ErrInvalidHeader, got %v", err)
+	}
+}
+
+func TestWriter_closed(t *testing.T) {
+	w, err := NewWriter(io.Discard, testKey(), chacha20poly1305.New)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	w.Close()
+
+	if _, err := w.Write([]byte("test")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+
+	if _, err := NewWriterSize(io.Discard, testKey(), chacha20poly1305.New, 10); !errors.Is(err, ErrInvalidChunkSize) {
+		t.Fatalf("expect ErrInvalidChunkSize, got %v", err)
+	}
+}
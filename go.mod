@@ -6,9 +6,9 @@ require (
 	github.com/CoboGlobal/cobo-waas2-go-sdk v1.4.0
 	github.com/ethereum/go-ethereum v1.14.12
 	github.com/gorilla/websocket v1.5.3
-	github.com/hashicorp/vault v1.18.2
 	github.com/herumi/bls-go-binary v1.35.1
 	github.com/rabbitmq/amqp091-go v1.10.0
+	golang.org/x/crypto v0.27.0
 )
 
 require (
@@ -16,7 +16,6 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/holiman/uint256 v1.3.1 // indirect
 	github.com/shopspring/decimal v1.4.0 // indirect
-	golang.org/x/crypto v0.27.0 // indirect
 	golang.org/x/oauth2 v0.23.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
 )
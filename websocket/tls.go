@@ -0,0 +1,20 @@
+package websocket
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// ListenAndServeTLS 在 addr 上以 TLS 提供 InitSocketServer 注册的 /ws 服务。
+// cfg 通常由 pki.ServerTLSConfig 生成，设置 ClientCAs 时即为 mTLS
+func ListenAndServeTLS(addr string, cfg *tls.Config) error {
+	srv := &http.Server{
+		Addr:              addr,
+		TLSConfig:         cfg,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// 证书已经在 cfg 中，不需要再从文件读取
+	return srv.ListenAndServeTLS("", "")
+}